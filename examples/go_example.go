@@ -29,12 +29,12 @@ func main() {
 	if err != nil {
 		fmt.Printf("✗ Rate not found: %v\n\n", err)
 	} else {
-		fmt.Printf("✓ BTC funding rate: %.4f%%\n\n", rate)
+		fmt.Printf("✓ BTC funding rate: %.4f%%\n\n", rate*100)
 	}
 
 	// Find arbitrage opportunities
 	fmt.Println("Finding arbitrage opportunities for BTC (min spread: 0.01%)...")
-	opportunities, err := client.FindArbitrageOpportunities("BTC", 0.01)
+	opportunities, err := client.FindArbitrageOpportunities("BTC", 0.0001)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -47,9 +47,9 @@ func main() {
 			max = len(opportunities)
 		}
 		for i, opp := range opportunities[:max] {
-			fmt.Printf("\n%d. %s - Spread: %.4f%%\n", i+1, opp.Symbol, opp.Spread)
-			fmt.Printf("   Long:  %s (%.4f%%)\n", opp.LongExchange, opp.Rate1)
-			fmt.Printf("   Short: %s (%.4f%%)\n", opp.ShortExchange, opp.Rate2)
+			fmt.Printf("\n%d. %s - Spread: %.4f%%\n", i+1, opp.Symbol, opp.Spread*100)
+			fmt.Printf("   Long:  %s (%.4f%%)\n", opp.LongExchange, opp.LongRate()*100)
+			fmt.Printf("   Short: %s (%.4f%%)\n", opp.ShortExchange, opp.ShortRate()*100)
 		}
 	}
 }
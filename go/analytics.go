@@ -0,0 +1,392 @@
+package onlyfunding
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// BreakEvenSpread returns the per-interval spread needed to cover round-trip
+// trading fees over a holding period of the given number of funding
+// intervals. longFee and shortFee are the fee rates charged per trade on each
+// venue, in the same decimal units as ArbitrageOpportunity.Spread; each leg
+// pays once to open and once to close. Pass the result as minSpread to the
+// arbitrage scans. It returns +Inf if intervals is not positive.
+func BreakEvenSpread(longFee, shortFee float64, intervals int) float64 {
+	if intervals <= 0 {
+		return math.Inf(1)
+	}
+	return 2 * (longFee + shortFee) / float64(intervals)
+}
+
+// SymbolDifference compares the symbols two exchanges report, returning the
+// symbols only exchangeA lists, only exchangeB lists, and both list. Only
+// symbols in both can be arbitraged between the two venues. All slices are
+// sorted. An exchange without rates in data is an error matching
+// ErrExchangeNotFound.
+func SymbolDifference(data *FundingRatesData, exchangeA, exchangeB string) (onlyA, onlyB, both []string, err error) {
+	ratesA, ok := data.FundingRates[exchangeA]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("%w: %s", ErrExchangeNotFound, exchangeA)
+	}
+	ratesB, ok := data.FundingRates[exchangeB]
+	if !ok {
+		return nil, nil, nil, fmt.Errorf("%w: %s", ErrExchangeNotFound, exchangeB)
+	}
+
+	for symbol := range ratesA {
+		if _, ok := ratesB[symbol]; ok {
+			both = append(both, symbol)
+		} else {
+			onlyA = append(onlyA, symbol)
+		}
+	}
+	for symbol := range ratesB {
+		if _, ok := ratesA[symbol]; !ok {
+			onlyB = append(onlyB, symbol)
+		}
+	}
+
+	sort.Strings(onlyA)
+	sort.Strings(onlyB)
+	sort.Strings(both)
+	return onlyA, onlyB, both, nil
+}
+
+// SpreadsVsReference returns each other exchange's signed spread against the
+// reference exchange for symbol, as that exchange's rate minus the
+// reference's, in decimal units. A positive value means longs pay more on that
+// exchange than on the reference. The reference itself is omitted. It is an
+// error if the reference has no rate for symbol.
+func (d *FundingRatesData) SpreadsVsReference(symbol, reference string) (map[string]float64, error) {
+	rates := d.symbolRates(symbol)
+	ref, ok := rates[reference]
+	if !ok {
+		return nil, fmt.Errorf("%w for %s on %s", ErrRateNotFound, symbol, reference)
+	}
+
+	spreads := make(map[string]float64, len(rates)-1)
+	for exchange, rate := range rates {
+		if exchange == reference {
+			continue
+		}
+		spreads[exchange] = float64(rate-ref) / 10000.0
+	}
+	return spreads, nil
+}
+
+// RatePercentile returns where exchange's rate for symbol falls among all
+// exchanges' rates for it, from 0 for the lowest to 100 for the highest payer.
+// Tied rates share the midpoint of their positions. An exchange that is the
+// only one listing symbol is at 100. It is an error if the exchange has no
+// rate for symbol.
+func (d *FundingRatesData) RatePercentile(symbol, exchange string) (float64, error) {
+	rates := d.symbolRates(symbol)
+	rate, ok := rates[exchange]
+	if !ok {
+		return 0, fmt.Errorf("%w for %s on %s", ErrRateNotFound, symbol, exchange)
+	}
+	if len(rates) == 1 {
+		return 100, nil
+	}
+
+	below, ties := 0, 0
+	for other, r := range rates {
+		switch {
+		case other == exchange:
+		case r < rate:
+			below++
+		case r == rate:
+			ties++
+		}
+	}
+	return 100 * (float64(below) + float64(ties)/2) / float64(len(rates)-1), nil
+}
+
+// ConsensusMode selects how ConsensusRate combines exchanges' rates
+type ConsensusMode int
+
+const (
+	// ConsensusMean weights every exchange equally
+	ConsensusMean ConsensusMode = iota
+	// ConsensusMedian takes the middle rate, or the mean of the two middle
+	// rates, which resists a single outlying venue
+	ConsensusMedian
+)
+
+func (m ConsensusMode) String() string {
+	if m == ConsensusMedian {
+		return "median"
+	}
+	return "mean"
+}
+
+// ConsensusRate combines every exchange's rate for symbol into a single
+// benchmark rate, in decimal units, to compare each venue against. There is no
+// open-interest-weighted mode: OIRankings ranks symbols, not venues, so all of
+// a symbol's rates would carry the same weight. It is an error if no exchange
+// reports symbol.
+func (d *FundingRatesData) ConsensusRate(symbol string, mode ConsensusMode) (float64, error) {
+	rates := d.symbolRates(symbol)
+	if len(rates) == 0 {
+		return 0, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+
+	values := make([]int, 0, len(rates))
+	for _, rate := range rates {
+		values = append(values, rate)
+	}
+
+	if mode == ConsensusMedian {
+		sort.Ints(values)
+		mid := len(values) / 2
+		if len(values)%2 == 0 {
+			return float64(values[mid-1]+values[mid]) / 2 / 10000.0, nil
+		}
+		return float64(values[mid]) / 10000.0, nil
+	}
+
+	sum := 0
+	for _, v := range values {
+		sum += v
+	}
+	return float64(sum) / float64(len(values)) / 10000.0, nil
+}
+
+// LadderRung is one venue on a FundingLadder
+type LadderRung struct {
+	Exchange string
+	Rate     float64
+	// GapToNext is the next rung's rate minus this one's, 0 on the top rung
+	GapToNext float64
+}
+
+// FundingLadder orders the exchanges reporting symbol by rate, lowest first,
+// so the bottom rung is the best venue to be long and the top the best to be
+// short, with the incremental spread between neighbours. Equal rates are
+// ordered by exchange name. It is an error if no exchange reports symbol.
+func (d *FundingRatesData) FundingLadder(symbol string) ([]LadderRung, error) {
+	rates := d.symbolRates(symbol)
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+
+	exchanges := make([]string, 0, len(rates))
+	for exchange := range rates {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Slice(exchanges, func(i, j int) bool {
+		if rates[exchanges[i]] != rates[exchanges[j]] {
+			return rates[exchanges[i]] < rates[exchanges[j]]
+		}
+		return exchanges[i] < exchanges[j]
+	})
+
+	ladder := make([]LadderRung, len(exchanges))
+	for i, exchange := range exchanges {
+		ladder[i] = LadderRung{Exchange: exchange, Rate: float64(rates[exchange]) / 10000.0}
+		if i+1 < len(exchanges) {
+			ladder[i].GapToNext = float64(rates[exchanges[i+1]]-rates[exchange]) / 10000.0
+		}
+	}
+	return ladder, nil
+}
+
+// AverageAbsSpreadPerSymbol returns, for each symbol reported by at least two
+// exchanges, the mean absolute spread over every pair of exchanges reporting
+// it, in decimal units. Persistently high values point to structurally
+// dislocated markets.
+func AverageAbsSpreadPerSymbol(data *FundingRatesData) map[string]float64 {
+	exchanges := make([]string, 0, len(data.FundingRates))
+	for exchange := range data.FundingRates {
+		exchanges = append(exchanges, exchange)
+	}
+	// sum in a fixed order so the float results are reproducible
+	sort.Strings(exchanges)
+
+	bySymbol := make(map[string][]int)
+	for _, exchange := range exchanges {
+		for symbol, rate := range data.FundingRates[exchange] {
+			bySymbol[symbol] = append(bySymbol[symbol], rate)
+		}
+	}
+
+	averages := make(map[string]float64)
+	for symbol, rates := range bySymbol {
+		if len(rates) < 2 {
+			continue
+		}
+		var sum float64
+		pairs := 0
+		for i := range rates {
+			for j := i + 1; j < len(rates); j++ {
+				sum += abs(float64(rates[i]-rates[j]) / 10000.0)
+				pairs++
+			}
+		}
+		averages[symbol] = sum / float64(pairs)
+	}
+	return averages
+}
+
+// OpportunityRank returns where o's spread ranks among every opportunity of
+// at least minSpread across all symbols in d, 1 being the widest, together
+// with how many opportunities there are. Ties share the best rank, so two
+// opportunities tied for widest are both 1 and the next is 3. The scan
+// applies no client options. It is an error if o, matched by ID, is not among
+// the opportunities.
+func (d *FundingRatesData) OpportunityRank(o ArbitrageOpportunity, minSpread float64) (int, int, error) {
+	var all []ArbitrageOpportunity
+	for _, symbol := range d.Symbols {
+		all = append(all, d.arbitrageOpportunities(symbol, minSpread, &scanConfig{})...)
+	}
+
+	id := o.ID()
+	var spread int
+	found := false
+	for _, opp := range all {
+		if opp.ID() == id {
+			spread, found = opp.SpreadBP, true
+			break
+		}
+	}
+	if !found {
+		return 0, 0, fmt.Errorf("opportunity %s %s not found", o.Symbol, o.PairKey())
+	}
+
+	rank := 1
+	for _, opp := range all {
+		if opp.SpreadBP > spread {
+			rank++
+		}
+	}
+	return rank, len(all), nil
+}
+
+// BestLongMarket returns the exchange and symbol where a long position is
+// paid the most funding. Positive rates mean longs pay shorts, so this is the
+// most negative rate in data; if every rate is positive it is the one costing
+// longs least. Ties go to the alphabetically first exchange, then symbol. The
+// bool is false if data has no rates.
+func BestLongMarket(data *FundingRatesData) (SymbolRate, bool) {
+	return bestMarket(data, func(rate, best int) bool { return rate < best })
+}
+
+// BestShortMarket returns the exchange and symbol where a short position is
+// paid the most funding: the most positive rate in data, since positive rates
+// mean longs pay shorts. Ties and the bool are as for BestLongMarket.
+func BestShortMarket(data *FundingRatesData) (SymbolRate, bool) {
+	return bestMarket(data, func(rate, best int) bool { return rate > best })
+}
+
+// bestMarket returns the rate in data that better ranks above all others
+func bestMarket(data *FundingRatesData, better func(rate, best int) bool) (SymbolRate, bool) {
+	var best SymbolRate
+	var bestRate int
+	found := false
+	for exchange, symbols := range data.FundingRates {
+		for symbol, rate := range symbols {
+			tieWin := rate == bestRate &&
+				(exchange < best.Exchange || (exchange == best.Exchange && symbol < best.Symbol))
+			if found && !better(rate, bestRate) && !tieWin {
+				continue
+			}
+			best = SymbolRate{Exchange: exchange, Symbol: symbol, Rate: float64(rate) / 10000.0}
+			bestRate, found = rate, true
+		}
+	}
+	return best, found
+}
+
+// FilterOpportunities returns the opportunities keep returns true for, in
+// their original order. opps is not modified.
+func FilterOpportunities(opps []ArbitrageOpportunity, keep func(ArbitrageOpportunity) bool) []ArbitrageOpportunity {
+	var kept []ArbitrageOpportunity
+	for _, opp := range opps {
+		if keep(opp) {
+			kept = append(kept, opp)
+		}
+	}
+	return kept
+}
+
+// SpreadHistogram counts opportunities per spread bucket. The sorted
+// boundaries b0 < b1 < ... < bn define the buckets "<b0", "[b0,b1)", ...,
+// "[bn-1,bn)" and ">=bn": lower bounds are inclusive and upper bounds
+// exclusive, so a spread equal to a boundary lands in the bucket starting at
+// it. Every bucket is present in the result, even when empty. buckets may be
+// given in any order; they are sorted and duplicates dropped, since a repeated
+// boundary would only add an empty bucket.
+func SpreadHistogram(opps []ArbitrageOpportunity, buckets []float64) map[string]int {
+	sorted := append([]float64(nil), buckets...)
+	sort.Float64s(sorted)
+	var bounds []float64
+	for i, b := range sorted {
+		if i == 0 || b != sorted[i-1] {
+			bounds = append(bounds, b)
+		}
+	}
+
+	labels := make([]string, len(bounds)+1)
+	if len(bounds) == 0 {
+		labels[0] = "all"
+	} else {
+		labels[0] = "<" + formatBound(bounds[0])
+		for i := 1; i < len(bounds); i++ {
+			labels[i] = "[" + formatBound(bounds[i-1]) + "," + formatBound(bounds[i]) + ")"
+		}
+		labels[len(bounds)] = ">=" + formatBound(bounds[len(bounds)-1])
+	}
+
+	histogram := make(map[string]int, len(labels))
+	for _, label := range labels {
+		histogram[label] = 0
+	}
+	for _, opp := range opps {
+		i := sort.Search(len(bounds), func(i int) bool { return bounds[i] > opp.Spread })
+		histogram[labels[i]]++
+	}
+	return histogram
+}
+
+func formatBound(b float64) string {
+	return strconv.FormatFloat(b, 'g', -1, 64)
+}
+
+// hoursPerYear is used for annualization
+const hoursPerYear = 24 * 365
+
+// ImpliedAPR annualizes NetFundingYield by simple (non-compounded) scaling:
+// the yield per interval times the number of intervals in a year. It returns
+// 0 if intervalHours is not positive.
+func (o ArbitrageOpportunity) ImpliedAPR(intervalHours int) float64 {
+	if intervalHours <= 0 {
+		return 0
+	}
+	return o.NetFundingYield() * float64(hoursPerYear) / float64(intervalHours)
+}
+
+// ImpliedAPY annualizes NetFundingYield assuming the funding collected is
+// reinvested every interval: (1 + yield)^intervals - 1. It returns 0 if
+// intervalHours is not positive.
+func (o ArbitrageOpportunity) ImpliedAPY(intervalHours int) float64 {
+	if intervalHours <= 0 {
+		return 0
+	}
+	periods := float64(hoursPerYear) / float64(intervalHours)
+	return math.Pow(1+o.NetFundingYield(), periods) - 1
+}
+
+// HedgeRatio returns how many contracts to short on ShortExchange per
+// contract bought on LongExchange so the two legs carry equal notional:
+// longPrice / shortPrice. It assumes linear contracts on the same underlying
+// with equal contract sizes, so a contract's notional is its mark price; scale
+// the result by the ratio of contract sizes if they differ. It returns 0 if
+// either price is not positive.
+func (o ArbitrageOpportunity) HedgeRatio(longPrice, shortPrice float64) float64 {
+	if longPrice <= 0 || shortPrice <= 0 {
+		return 0
+	}
+	return longPrice / shortPrice
+}
@@ -0,0 +1,18 @@
+package onlyfunding
+
+import "testing"
+
+func TestSpreadHistogramDedupesBounds(t *testing.T) {
+	opps := []ArbitrageOpportunity{{Spread: 0.0005}, {Spread: 0.001}, {Spread: 0.0015}, {Spread: 0.003}}
+
+	got := SpreadHistogram(opps, []float64{0.002, 0.001, 0.002, 0.001})
+	want := map[string]int{"<0.001": 1, "[0.001,0.002)": 2, ">=0.002": 1}
+	if len(got) != len(want) {
+		t.Fatalf("SpreadHistogram = %v, want %v", got, want)
+	}
+	for label, n := range want {
+		if got[label] != n {
+			t.Errorf("bucket %s = %d, want %d", label, got[label], n)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package onlyfunding
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Side is the direction of a perpetual position
+type Side int
+
+const (
+	// Long positions pay funding when the rate is positive
+	Long Side = iota
+	// Short positions receive funding when the rate is positive
+	Short
+)
+
+func (s Side) String() string {
+	if s == Short {
+		return "short"
+	}
+	return "long"
+}
+
+// Position is a perpetual position held on an exchange. Notional is its size
+// in quote currency and is expected to be positive; Side gives the direction.
+type Position struct {
+	Exchange string
+	Symbol   string
+	Notional float64
+	Side     Side
+}
+
+// BasketCarry returns the funding the whole book of positions collects per
+// funding interval at data's current rates, in quote currency. Funding
+// received is positive and funding paid is negative: a long pays
+// Notional×rate and a short receives it. Positions on an exchange or symbol
+// without a rate in data make it an error, listing every such position,
+// rather than counting as zero.
+func BasketCarry(data *FundingRatesData, positions []Position) (float64, error) {
+	var carry float64
+	var unknown []string
+	for _, p := range positions {
+		raw, ok := data.FundingRates[p.Exchange][p.Symbol]
+		if !ok {
+			unknown = append(unknown, p.Symbol+" on "+p.Exchange)
+			continue
+		}
+		payment := p.Notional * float64(raw) / 10000.0
+		if p.Side == Long {
+			payment = -payment
+		}
+		carry += payment
+	}
+	if len(unknown) > 0 {
+		return 0, fmt.Errorf("%w for %s", ErrRateNotFound, strings.Join(unknown, ", "))
+	}
+	return carry, nil
+}
@@ -0,0 +1,55 @@
+package onlyfunding
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// WithCacheTTL makes the client reuse the last full set of funding rates for
+// ttl before fetching again, so loops over GetRate or the scans don't cost a
+// round trip per call. Every caller gets its own deep copy of the cached data.
+// Requests narrowed by server-side filtering are never cached. Use Refresh to
+// bypass the cache. A non-positive ttl disables caching, the default.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(c *Client) {
+		c.cacheTTL = ttl
+	}
+}
+
+// rateCache holds the last full fetch
+type rateCache struct {
+	mu        sync.RWMutex
+	data      *FundingRatesData
+	fetchedAt time.Time
+}
+
+// get returns a copy of the cached data if it is younger than ttl
+func (rc *rateCache) get(ttl time.Duration) (*FundingRatesData, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	if rc.data == nil || time.Since(rc.fetchedAt) >= ttl {
+		return nil, false
+	}
+	return rc.data.clone(), true
+}
+
+// set caches a copy of data
+func (rc *rateCache) set(data *FundingRatesData) {
+	cp := data.clone()
+	rc.mu.Lock()
+	rc.data = cp
+	rc.fetchedAt = time.Now()
+	rc.mu.Unlock()
+}
+
+// Refresh fetches the full set of funding rates over the network, bypassing
+// and then updating the cache
+func (c *Client) Refresh() (*FundingRatesData, error) {
+	return c.RefreshContext(context.Background())
+}
+
+// RefreshContext is Refresh under ctx
+func (c *Client) RefreshContext(ctx context.Context) (*FundingRatesData, error) {
+	return c.fetchUncached(ctx, nil)
+}
@@ -0,0 +1,118 @@
+package onlyfunding
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingClient returns a client whose nth request is answered with the
+// example data and binance's BTC rate set to n, and the request counter
+func countingClient(t *testing.T, opts ...Option) (*Client, *int32) {
+	t.Helper()
+	var requests int32
+	stub := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		data := ExampleFundingRatesData()
+		data.FundingRates["binance_1_perp"]["BTC"] = int(atomic.AddInt32(&requests, 1))
+		body, err := json.Marshal(data)
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{contentTypeJSON}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	return NewClientWithOptions(DefaultBaseURL, time.Second, append([]Option{WithRoundTripper(stub)}, opts...)...), &requests
+}
+
+func TestCacheServesWithinTTL(t *testing.T) {
+	const ttl = 100 * time.Millisecond
+	c, requests := countingClient(t, WithCacheTTL(ttl))
+
+	for i := 0; i < 3; i++ {
+		data, err := c.GetFundingRates()
+		if err != nil {
+			t.Fatalf("GetFundingRates error: %v", err)
+		}
+		if got := data.FundingRates["binance_1_perp"]["BTC"]; got != 1 {
+			t.Errorf("call %d: BTC rate %d, want the first response's 1", i, got)
+		}
+	}
+	if got := atomic.LoadInt32(requests); got != 1 {
+		t.Errorf("%d requests within the TTL, want 1", got)
+	}
+
+	time.Sleep(ttl + 20*time.Millisecond)
+	data, err := c.GetFundingRates()
+	if err != nil {
+		t.Fatalf("GetFundingRates error: %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("%d requests after the TTL, want 2", got)
+	}
+	if got := data.FundingRates["binance_1_perp"]["BTC"]; got != 2 {
+		t.Errorf("BTC rate %d after the TTL, want the fresh 2", got)
+	}
+}
+
+func TestCacheReturnsIndependentCopies(t *testing.T) {
+	c, _ := countingClient(t, WithCacheTTL(time.Hour))
+
+	first, err := c.GetFundingRates()
+	if err != nil {
+		t.Fatalf("GetFundingRates error: %v", err)
+	}
+	first.FundingRates["binance_1_perp"]["BTC"] = 999
+	first.Symbols[0] = "MUTATED"
+	delete(first.FundingRates, "okx_1_perp")
+
+	second, err := c.GetFundingRates()
+	if err != nil {
+		t.Fatalf("GetFundingRates error: %v", err)
+	}
+	if got := second.FundingRates["binance_1_perp"]["BTC"]; got != 1 {
+		t.Errorf("cached BTC rate = %d after mutating a returned copy, want 1", got)
+	}
+	if second.Symbols[0] != "BTC" {
+		t.Errorf("cached Symbols = %v after mutating a returned copy", second.Symbols)
+	}
+	if _, ok := second.FundingRates["okx_1_perp"]; !ok {
+		t.Error("cached okx_1_perp rates gone after mutating a returned copy")
+	}
+}
+
+func TestRefreshBypassesAndRepopulatesCache(t *testing.T) {
+	c, requests := countingClient(t, WithCacheTTL(time.Hour))
+
+	if _, err := c.GetFundingRates(); err != nil {
+		t.Fatalf("GetFundingRates error: %v", err)
+	}
+	refreshed, err := c.Refresh()
+	if err != nil {
+		t.Fatalf("Refresh error: %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("%d requests, want Refresh to bypass the cache", got)
+	}
+	if got := refreshed.FundingRates["binance_1_perp"]["BTC"]; got != 2 {
+		t.Errorf("Refresh BTC rate = %d, want 2", got)
+	}
+
+	cached, err := c.GetFundingRates()
+	if err != nil {
+		t.Fatalf("GetFundingRates error: %v", err)
+	}
+	if got := atomic.LoadInt32(requests); got != 2 {
+		t.Errorf("%d requests, want the refreshed data served from the cache", got)
+	}
+	if got := cached.FundingRates["binance_1_perp"]["BTC"]; got != 2 {
+		t.Errorf("cached BTC rate = %d after Refresh, want 2", got)
+	}
+}
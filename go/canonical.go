@@ -0,0 +1,48 @@
+package onlyfunding
+
+import (
+	"regexp"
+	"sort"
+)
+
+// venueSuffix matches the account/venue numbering on exchange keys, as in
+// binance_1_perp
+var venueSuffix = regexp.MustCompile(`_\d+_perp$`)
+
+// CanonicalExchange strips the _N_perp suffix from an exchange key, so
+// binance_1_perp and binance_2_perp both become binance. Keys without the
+// suffix are returned unchanged.
+func CanonicalExchange(raw string) string {
+	return venueSuffix.ReplaceAllString(raw, "")
+}
+
+// RawExchanges returns the sorted exchange keys in the data that canonicalize
+// to canonical
+func (d *FundingRatesData) RawExchanges(canonical string) []string {
+	var raw []string
+	for exchange := range d.FundingRates {
+		if CanonicalExchange(exchange) == canonical {
+			raw = append(raw, exchange)
+		}
+	}
+	sort.Strings(raw)
+	return raw
+}
+
+// CanonicalRates returns a symbol's rate per canonical exchange. When several
+// keys of the same venue report the symbol, their rates are averaged.
+func (d *FundingRatesData) CanonicalRates(symbol string) map[string]float64 {
+	sums := make(map[string]int)
+	counts := make(map[string]int)
+	for exchange, rate := range d.symbolRates(symbol) {
+		canonical := CanonicalExchange(exchange)
+		sums[canonical] += rate
+		counts[canonical]++
+	}
+
+	rates := make(map[string]float64, len(sums))
+	for canonical, sum := range sums {
+		rates[canonical] = float64(sum) / float64(counts[canonical]) / 10000.0
+	}
+	return rates
+}
@@ -0,0 +1,57 @@
+package onlyfunding
+
+import "sync"
+
+// WithRequestCoalescing makes concurrent identical fetches share a single
+// in-flight request, so a burst of callers hitting a cold client costs one
+// round trip. Every caller gets its own copy of the result. Callers that
+// join an in-flight request share its outcome, including a cancellation of
+// the context it was started with.
+func WithRequestCoalescing() Option {
+	return func(c *Client) {
+		c.coalesce = true
+	}
+}
+
+// flightGroup deduplicates concurrent calls with the same key
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	wg   sync.WaitGroup
+	data *FundingRatesData
+	err  error
+	dups int
+}
+
+// do runs fn once for all concurrent callers with the same key. shared
+// reports whether the result was handed to more than one caller.
+func (g *flightGroup) do(key string, fn func() (*FundingRatesData, error)) (data *FundingRatesData, shared bool, err error) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		call.dups++
+		g.mu.Unlock()
+		call.wg.Wait()
+		return call.data, true, call.err
+	}
+
+	call := &flightCall{}
+	call.wg.Add(1)
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	call.data, call.err = fn()
+	call.wg.Done()
+
+	g.mu.Lock()
+	delete(g.calls, key)
+	shared = call.dups > 0
+	g.mu.Unlock()
+
+	return call.data, shared, call.err
+}
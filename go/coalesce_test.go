@@ -0,0 +1,82 @@
+package onlyfunding
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForJoiners blocks until n callers have joined the in-flight call for key
+func waitForJoiners(t *testing.T, g *flightGroup, key string, n int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		call, ok := g.calls[key]
+		joined := ok && call.dups >= n
+		g.mu.Unlock()
+		if joined {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("%d callers never joined the in-flight request", n)
+}
+
+// TestRequestCoalescingSharesOneRequest is meant to be run with -race: the
+// callers mutate their results concurrently, which only passes if each got
+// its own copy
+func TestRequestCoalescingSharesOneRequest(t *testing.T) {
+	const callers = 8
+	var hits int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.Write(ExampleFundingRatesJSON())
+	}))
+	defer srv.Close()
+	var once sync.Once
+	unblock := func() { once.Do(func() { close(release) }) }
+	defer unblock()
+	c := NewClientWithOptions(srv.URL, 5*time.Second, WithRequestCoalescing())
+
+	results := make([]*FundingRatesData, callers)
+	errs := make([]error, callers)
+	var wg sync.WaitGroup
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], errs[i] = c.GetFundingRates()
+			if errs[i] == nil {
+				results[i].FundingRates["binance_1_perp"]["BTC"] = i
+				results[i].Symbols[0] = "MUTATED"
+			}
+		}(i)
+	}
+	waitForJoiners(t, &c.flight, "", callers-1)
+	unblock()
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil {
+			t.Fatalf("caller %d: %v", i, errs[i])
+		}
+		if got := results[i].FundingRates["binance_1_perp"]["BTC"]; got != i {
+			t.Errorf("caller %d sees BTC rate %d, want its own write %d", i, got, i)
+		}
+		for j := 0; j < i; j++ {
+			if results[i] == results[j] {
+				t.Errorf("callers %d and %d got the same *FundingRatesData", j, i)
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package onlyfunding
+
+import (
+	"sort"
+	"time"
+)
+
+// ClientConfig is a snapshot of a client's effective configuration, for
+// debugging and support. It is safe to log: hooks such as a custom HTTP
+// client, middleware or a decoder are reported only by presence or count.
+type ClientConfig struct {
+	BaseURL                string                     `json:"base_url"`
+	Timeout                time.Duration              `json:"timeout"`
+	AcceptContentType      string                     `json:"accept_content_type"`
+	AcceptEncodings        []string                   `json:"accept_encodings,omitempty"`
+	MaxIdleConns           int                        `json:"max_idle_conns,omitempty"`
+	IdleConnTimeout        time.Duration              `json:"idle_conn_timeout,omitempty"`
+	KeepAlive              time.Duration              `json:"keep_alive,omitempty"`
+	HTTPVersion            string                     `json:"http_version"`
+	CustomHTTPClient       bool                       `json:"custom_http_client"`
+	CustomRoundTripper     bool                       `json:"custom_round_tripper"`
+	Middleware             int                        `json:"middleware"`
+	JSONLogger             bool                       `json:"json_logger"`
+	Recorder               bool                       `json:"recorder"`
+	CustomDecoder          bool                       `json:"custom_decoder"`
+	RetryOnTimeout         bool                       `json:"retry_on_timeout"`
+	AttemptTimeout         time.Duration              `json:"attempt_timeout,omitempty"`
+	MaxAttempts            int                        `json:"max_attempts,omitempty"`
+	RetryBaseDelay         time.Duration              `json:"retry_base_delay,omitempty"`
+	FundingIntervals       map[string]time.Duration   `json:"funding_intervals,omitempty"`
+	FundingSchedules       map[string]FundingSchedule `json:"funding_schedules,omitempty"`
+	AllowedPairs           [][2]string                `json:"allowed_pairs,omitempty"`
+	MinOIRank              int                        `json:"min_oi_rank,omitempty"`
+	IncludeUnranked        bool                       `json:"include_unranked"`
+	MinExchanges           int                        `json:"min_exchanges"`
+	ServerSideFiltering    bool                       `json:"server_side_filtering"`
+	RequestCoalescing      bool                       `json:"request_coalescing"`
+	SortKey                string                     `json:"sort_key"`
+	IncludeZeroSpread      bool                       `json:"include_zero_spread"`
+	Endpoints              []string                   `json:"endpoints,omitempty"`
+	ExchangeReconciliation string                     `json:"exchange_reconciliation"`
+	HourlyNormalization    bool                       `json:"hourly_normalization"`
+	CacheTTL               time.Duration              `json:"cache_ttl,omitempty"`
+}
+
+// Config returns the client's resolved configuration
+func (c *Client) Config() ClientConfig {
+	cfg := ClientConfig{
+		BaseURL:                c.baseURL,
+		Timeout:                c.timeout,
+		AcceptContentType:      c.acceptContentType,
+		AcceptEncodings:        append([]string(nil), c.acceptEncodings...),
+		MaxIdleConns:           c.maxIdleConns,
+		IdleConnTimeout:        c.idleConnTimeout,
+		KeepAlive:              c.keepAlive,
+		HTTPVersion:            c.httpVersion.String(),
+		CustomHTTPClient:       c.httpClient != nil,
+		CustomRoundTripper:     c.roundTripper != nil,
+		Middleware:             len(c.middleware),
+		JSONLogger:             c.jsonLogger != nil,
+		Recorder:               c.recorder != nil,
+		CustomDecoder:          c.jsonDecoder != nil,
+		RetryOnTimeout:         c.retryOnTimeout,
+		AttemptTimeout:         c.attemptTimeout,
+		MaxAttempts:            c.maxAttempts,
+		RetryBaseDelay:         c.baseDelay,
+		MinOIRank:              c.scan.minOIRank,
+		IncludeUnranked:        c.scan.includeUnranked,
+		MinExchanges:           c.scan.requiredExchanges(),
+		ServerSideFiltering:    c.serverSideFiltering,
+		RequestCoalescing:      c.coalesce,
+		SortKey:                c.scan.sortKey.String(),
+		IncludeZeroSpread:      c.scan.includeZeroSpread,
+		ExchangeReconciliation: c.scan.reconciliation.String(),
+		HourlyNormalization:    c.scan.hourly,
+		CacheTTL:               c.cacheTTL,
+	}
+	if len(c.intervals) > 0 {
+		cfg.FundingIntervals = make(map[string]time.Duration, len(c.intervals))
+		for exchange, interval := range c.intervals {
+			cfg.FundingIntervals[exchange] = interval
+		}
+	}
+	if len(c.schedules) > 0 {
+		cfg.FundingSchedules = make(map[string]FundingSchedule, len(c.schedules))
+		for exchange, schedule := range c.schedules {
+			cfg.FundingSchedules[exchange] = schedule
+		}
+	}
+	for pair := range c.scan.allowedPairs {
+		cfg.AllowedPairs = append(cfg.AllowedPairs, pair)
+	}
+	sort.Slice(cfg.AllowedPairs, func(i, j int) bool {
+		if cfg.AllowedPairs[i][0] != cfg.AllowedPairs[j][0] {
+			return cfg.AllowedPairs[i][0] < cfg.AllowedPairs[j][0]
+		}
+		return cfg.AllowedPairs[i][1] < cfg.AllowedPairs[j][1]
+	})
+	if c.router != nil {
+		c.router.mu.Lock()
+		for _, endpoint := range c.router.endpoints {
+			cfg.Endpoints = append(cfg.Endpoints, endpoint.URL)
+		}
+		c.router.mu.Unlock()
+	}
+	return cfg
+}
@@ -0,0 +1,45 @@
+package onlyfunding
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestConfigReportsHooks(t *testing.T) {
+	c := NewClientWithOptions(DefaultBaseURL, time.Second)
+	cfg := c.Config()
+	if cfg.CustomHTTPClient || cfg.CustomRoundTripper || cfg.Middleware != 0 ||
+		cfg.JSONLogger || cfg.Recorder || cfg.CustomDecoder {
+		t.Errorf("default Config() reports hooks: %+v", cfg)
+	}
+
+	recorder := NewRecorder(io.Discard, 0, RecorderDrop)
+	defer recorder.Close()
+	passthrough := func(next http.RoundTripper) http.RoundTripper { return next }
+	c = NewClientWithOptions(DefaultBaseURL, time.Second,
+		WithHTTPClient(&http.Client{}),
+		WithRoundTripper(http.DefaultTransport),
+		WithMiddleware(passthrough),
+		WithMiddleware(passthrough),
+		WithJSONLogger(io.Discard),
+		WithRecorder(recorder),
+		WithDecoder(func(r io.Reader, v interface{}) error {
+			return json.NewDecoder(r).Decode(v)
+		}))
+	cfg = c.Config()
+	if !cfg.CustomHTTPClient || !cfg.CustomRoundTripper || cfg.Middleware != 2 ||
+		!cfg.JSONLogger || !cfg.Recorder || !cfg.CustomDecoder {
+		t.Errorf("Config() = %+v, want every hook reported", cfg)
+	}
+}
+
+func TestConfigCopiesAcceptEncodings(t *testing.T) {
+	c := NewClientWithOptions(DefaultBaseURL, time.Second, WithAcceptEncodings([]string{"gzip"}))
+	c.Config().AcceptEncodings[0] = "br"
+	if got := c.Config().AcceptEncodings; len(got) != 1 || got[0] != "gzip" {
+		t.Errorf("AcceptEncodings = %v after mutating a snapshot, want [gzip]", got)
+	}
+}
@@ -0,0 +1,37 @@
+package onlyfunding
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// CorrelationIDHeader is the request header carrying the correlation ID
+const CorrelationIDHeader = "X-Correlation-ID"
+
+type correlationIDKey struct{}
+
+// WithCorrelationID returns a copy of ctx carrying id, which is sent as the
+// X-Correlation-ID header on every request made with the context
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, correlationIDKey{}, id)
+}
+
+// CorrelationIDFromContext returns the correlation ID stored in ctx, if any
+func CorrelationIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(correlationIDKey{}).(string)
+	return id, ok && id != ""
+}
+
+// correlationID returns the ID from ctx, or a new random one so every request
+// is traceable
+func correlationID(ctx context.Context) string {
+	if id, ok := CorrelationIDFromContext(ctx); ok {
+		return id
+	}
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,147 @@
+package onlyfunding
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"sort"
+)
+
+const contentTypeJSON = "application/json"
+
+// responseDecoder decodes a response body into data
+type responseDecoder func(body io.Reader, data *FundingRatesData) error
+
+// responseDecoders maps a response media type to its decoder. New wire formats
+// are supported by adding an entry here.
+var responseDecoders = map[string]responseDecoder{
+	contentTypeJSON: decodeJSON,
+}
+
+// decoderFor picks the decoder for a response Content-Type. Responses without
+// a Content-Type are passed through to the JSON decoder. It returns false if
+// no decoder is registered for the type.
+func decoderFor(contentType string) (responseDecoder, bool) {
+	if contentType == "" {
+		return decodeJSON, true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, false
+	}
+	decode, ok := responseDecoders[mediaType]
+	return decode, ok
+}
+
+// WithDecoder replaces the standard library's streaming JSON decoder for JSON
+// responses, e.g. with a faster third-party library, without the SDK
+// depending on it. decode is called with the response body and a
+// *FundingRatesData. To keep the lenient handling of OI ranks and malformed
+// exchanges, the library must honor json.Unmarshaler.
+func WithDecoder(decode func(r io.Reader, v interface{}) error) Option {
+	return func(c *Client) {
+		c.jsonDecoder = decode
+	}
+}
+
+// decoderFor picks the decoder for a response Content-Type, using the
+// decoder given to WithDecoder for JSON if there is one
+func (c *Client) decoderFor(contentType string) (responseDecoder, bool) {
+	decode, ok := decoderFor(contentType)
+	if !ok || c.jsonDecoder == nil {
+		return decode, ok
+	}
+	if mediaType, _, _ := mime.ParseMediaType(contentType); contentType != "" && mediaType != contentTypeJSON {
+		return decode, ok
+	}
+	return func(body io.Reader, data *FundingRatesData) error {
+		return c.jsonDecoder(body, data)
+	}, true
+}
+
+func decodeJSON(body io.Reader, data *FundingRatesData) error {
+	return json.NewDecoder(body).Decode(data)
+}
+
+// DecodeWarning records an exchange whose rates were skipped because they
+// failed to decode
+type DecodeWarning struct {
+	Exchange string
+	Err      error
+}
+
+func (w DecodeWarning) String() string {
+	return fmt.Sprintf("skipped rates for %s: %v", w.Exchange, w.Err)
+}
+
+// DecodeWarnings returns the exchanges skipped while decoding, if any
+func (d *FundingRatesData) DecodeWarnings() []DecodeWarning {
+	return d.warnings
+}
+
+// UnmarshalJSON decodes the API response, accepting OI ranks sent either as
+// JSON strings or as bare numbers. An exchange whose funding_rates entry is
+// malformed is skipped and recorded in DecodeWarnings rather than failing the
+// whole response.
+func (d *FundingRatesData) UnmarshalJSON(b []byte) error {
+	type plain FundingRatesData
+	aux := struct {
+		*plain
+		FundingRates  map[string]json.RawMessage `json:"funding_rates"`
+		OIRankings    map[string]flexString      `json:"oi_rankings"`
+		DefaultOIRank flexString                 `json:"default_oi_rank"`
+	}{plain: (*plain)(d)}
+
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+
+	d.FundingRates = nil
+	d.warnings = nil
+	if aux.FundingRates != nil {
+		d.FundingRates = make(map[string]map[string]int, len(aux.FundingRates))
+		for exchange, raw := range aux.FundingRates {
+			var rates map[string]int
+			if err := json.Unmarshal(raw, &rates); err != nil {
+				d.warnings = append(d.warnings, DecodeWarning{Exchange: exchange, Err: err})
+				continue
+			}
+			d.FundingRates[exchange] = rates
+		}
+		sort.Slice(d.warnings, func(i, j int) bool {
+			return d.warnings[i].Exchange < d.warnings[j].Exchange
+		})
+	}
+
+	d.OIRankings = nil
+	if aux.OIRankings != nil {
+		d.OIRankings = make(map[string]string, len(aux.OIRankings))
+		for symbol, rank := range aux.OIRankings {
+			d.OIRankings[symbol] = string(rank)
+		}
+	}
+	d.DefaultOIRank = string(aux.DefaultOIRank)
+	return nil
+}
+
+// flexString decodes from a JSON string or number, keeping the number's text
+type flexString string
+
+func (s *flexString) UnmarshalJSON(b []byte) error {
+	if len(b) > 0 && b[0] == '"' {
+		var str string
+		if err := json.Unmarshal(b, &str); err != nil {
+			return err
+		}
+		*s = flexString(str)
+		return nil
+	}
+
+	var num json.Number
+	if err := json.Unmarshal(b, &num); err != nil {
+		return err
+	}
+	*s = flexString(num)
+	return nil
+}
@@ -0,0 +1,133 @@
+package onlyfunding
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestUnmarshalOIRanksStringsAndNumbers(t *testing.T) {
+	tests := []struct {
+		name        string
+		json        string
+		wantRanks   map[string]string
+		wantDefault string
+	}{
+		{
+			name:        "strings",
+			json:        `{"oi_rankings": {"BTC": "1", "ETH": "2"}, "default_oi_rank": "500+"}`,
+			wantRanks:   map[string]string{"BTC": "1", "ETH": "2"},
+			wantDefault: "500+",
+		},
+		{
+			name:        "numbers",
+			json:        `{"oi_rankings": {"BTC": 1, "ETH": 2}, "default_oi_rank": 500}`,
+			wantRanks:   map[string]string{"BTC": "1", "ETH": "2"},
+			wantDefault: "500",
+		},
+		{
+			name:        "mixed",
+			json:        `{"oi_rankings": {"BTC": 1, "ETH": "2"}, "default_oi_rank": "500+"}`,
+			wantRanks:   map[string]string{"BTC": "1", "ETH": "2"},
+			wantDefault: "500+",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var data FundingRatesData
+			if err := json.Unmarshal([]byte(tt.json), &data); err != nil {
+				t.Fatalf("Unmarshal error: %v", err)
+			}
+			if len(data.OIRankings) != len(tt.wantRanks) {
+				t.Fatalf("OIRankings = %v, want %v", data.OIRankings, tt.wantRanks)
+			}
+			for symbol, want := range tt.wantRanks {
+				if got := data.OIRankings[symbol]; got != want {
+					t.Errorf("OIRankings[%s] = %q, want %q", symbol, got, want)
+				}
+			}
+			if data.DefaultOIRank != tt.wantDefault {
+				t.Errorf("DefaultOIRank = %q, want %q", data.DefaultOIRank, tt.wantDefault)
+			}
+		})
+	}
+}
+
+func TestUnmarshalOIRankRejectsOtherTypes(t *testing.T) {
+	var data FundingRatesData
+	if err := json.Unmarshal([]byte(`{"default_oi_rank": true}`), &data); err == nil {
+		t.Error("Unmarshal accepted a boolean default_oi_rank")
+	}
+}
+
+func TestOIRankParsing(t *testing.T) {
+	data := &FundingRatesData{
+		OIRankings:    map[string]string{"BTC": "1", "ETH": " 2 ", "BAD": "n/a"},
+		DefaultOIRank: "500+",
+	}
+
+	tests := []struct {
+		symbol  string
+		want    int
+		wantErr bool
+	}{
+		{"BTC", 1, false},
+		{"ETH", 2, false},
+		{"BAD", 0, true},
+		{"MISSING", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := data.OIRank(tt.symbol)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("OIRank(%s) = %d, %v; want %d, error %v", tt.symbol, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+	if got, err := data.DefaultOIRankValue(); err != nil || got != 500 {
+		t.Errorf("DefaultOIRankValue() = %d, %v; want 500", got, err)
+	}
+	if got, err := data.EffectiveOIRank("MISSING"); err != nil || got != 500 {
+		t.Errorf("EffectiveOIRank(MISSING) = %d, %v; want 500", got, err)
+	}
+
+	data.DefaultOIRank = "many"
+	if _, err := data.DefaultOIRankValue(); err == nil {
+		t.Error("DefaultOIRankValue() accepted \"many\"")
+	}
+}
+
+// benchmarkDecode decodes a large response through the decoder c picks for
+// JSON, the path requestFundingRates takes
+func benchmarkDecode(b *testing.B, c *Client) {
+	body, err := json.Marshal(benchmarkData())
+	if err != nil {
+		b.Fatal(err)
+	}
+	decode, ok := c.decoderFor(contentTypeJSON)
+	if !ok {
+		b.Fatal("no JSON decoder")
+	}
+	b.SetBytes(int64(len(body)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var data FundingRatesData
+		if err := decode(bytes.NewReader(body), &data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecodeBuiltin(b *testing.B) {
+	benchmarkDecode(b, NewClient())
+}
+
+// BenchmarkDecodeWithDecoder plugs the same standard library decoder in
+// through WithDecoder, so the difference from BenchmarkDecodeBuiltin is the
+// hook's overhead
+func BenchmarkDecodeWithDecoder(b *testing.B) {
+	benchmarkDecode(b, NewClient(WithDecoder(func(r io.Reader, v interface{}) error {
+		return json.NewDecoder(r).Decode(v)
+	})))
+}
@@ -0,0 +1,193 @@
+package onlyfunding
+
+import "sort"
+
+// ChangeKind classifies a RateChange
+type ChangeKind int
+
+const (
+	// RateChanged means the rate moved between snapshots
+	RateChanged ChangeKind = iota
+	// RateAdded means the rate only exists in the newer snapshot
+	RateAdded
+	// RateRemoved means the rate only exists in the older snapshot
+	RateRemoved
+)
+
+func (k ChangeKind) String() string {
+	switch k {
+	case RateAdded:
+		return "added"
+	case RateRemoved:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// RateChange is the difference in one exchange/symbol rate between two
+// snapshots. Old is 0 for added rates and New is 0 for removed ones.
+type RateChange struct {
+	Exchange string
+	Symbol   string
+	Old      float64
+	New      float64
+	Delta    float64
+	Kind     ChangeKind
+}
+
+// Diff returns every rate that was added, removed or changed between two
+// snapshots, ordered by exchange then symbol
+func Diff(old, new *FundingRatesData) []RateChange {
+	var changes []RateChange
+
+	for exchange, rates := range new.FundingRates {
+		for symbol, rate := range rates {
+			prev, ok := old.FundingRates[exchange][symbol]
+			switch {
+			case !ok:
+				changes = append(changes, RateChange{
+					Exchange: exchange,
+					Symbol:   symbol,
+					New:      float64(rate) / 10000.0,
+					Delta:    float64(rate) / 10000.0,
+					Kind:     RateAdded,
+				})
+			case prev != rate:
+				changes = append(changes, RateChange{
+					Exchange: exchange,
+					Symbol:   symbol,
+					Old:      float64(prev) / 10000.0,
+					New:      float64(rate) / 10000.0,
+					Delta:    float64(rate-prev) / 10000.0,
+					Kind:     RateChanged,
+				})
+			}
+		}
+	}
+
+	for exchange, rates := range old.FundingRates {
+		for symbol, rate := range rates {
+			if _, ok := new.FundingRates[exchange][symbol]; !ok {
+				changes = append(changes, RateChange{
+					Exchange: exchange,
+					Symbol:   symbol,
+					Old:      float64(rate) / 10000.0,
+					Delta:    -float64(rate) / 10000.0,
+					Kind:     RateRemoved,
+				})
+			}
+		}
+	}
+
+	sortRateChanges(changes)
+	return changes
+}
+
+// SignFlips returns the rates present in both snapshots whose sign flipped,
+// from positive (longs pay shorts) to negative (shorts pay longs) or back,
+// ordered by exchange then symbol. Moves to or from exactly zero don't count.
+func SignFlips(old, new *FundingRatesData) []RateChange {
+	var flips []RateChange
+	for exchange, rates := range new.FundingRates {
+		for symbol, rate := range rates {
+			prev, ok := old.FundingRates[exchange][symbol]
+			if !ok || !oppositeSigns(prev, rate) {
+				continue
+			}
+			flips = append(flips, RateChange{
+				Exchange: exchange,
+				Symbol:   symbol,
+				Old:      float64(prev) / 10000.0,
+				New:      float64(rate) / 10000.0,
+				Delta:    float64(rate-prev) / 10000.0,
+				Kind:     RateChanged,
+			})
+		}
+	}
+
+	sortRateChanges(flips)
+	return flips
+}
+
+// oppositeSigns reports whether a and b are non-zero with different signs
+func oppositeSigns(a, b int) bool {
+	return (a > 0 && b < 0) || (a < 0 && b > 0)
+}
+
+func sortRateChanges(changes []RateChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Exchange != changes[j].Exchange {
+			return changes[i].Exchange < changes[j].Exchange
+		}
+		return changes[i].Symbol < changes[j].Symbol
+	})
+}
+
+// OpportunityDelta is a change in an opportunity between two scans, matched by
+// ArbitrageOpportunity.ID. Kind is RateChanged for an opportunity present in
+// both scans whose spread widened, RateAdded for one only in the current scan
+// and RateRemoved for one only in the previous scan. Previous is zero for
+// added opportunities and Current for removed ones; Delta is the current
+// spread minus the previous.
+type OpportunityDelta struct {
+	ID       string
+	Previous ArbitrageOpportunity
+	Current  ArbitrageOpportunity
+	Delta    float64
+	Kind     ChangeKind
+}
+
+// ImprovedOpportunities compares two scans and returns the opportunities
+// whose spread widened, followed by those that newly appeared and those that
+// disappeared. Widened ones come largest delta first; the rest are ordered by
+// spread, widest first. Opportunities whose spread held or narrowed are left
+// out.
+func ImprovedOpportunities(prev, curr []ArbitrageOpportunity) []OpportunityDelta {
+	previous := make(map[string]ArbitrageOpportunity, len(prev))
+	for _, opp := range prev {
+		previous[opp.ID()] = opp
+	}
+	current := make(map[string]bool, len(curr))
+
+	var deltas []OpportunityDelta
+	for _, opp := range curr {
+		id := opp.ID()
+		current[id] = true
+		old, ok := previous[id]
+		switch {
+		case !ok:
+			deltas = append(deltas, OpportunityDelta{ID: id, Current: opp, Delta: opp.Spread, Kind: RateAdded})
+		case opp.Spread > old.Spread:
+			deltas = append(deltas, OpportunityDelta{ID: id, Previous: old, Current: opp, Delta: opp.Spread - old.Spread, Kind: RateChanged})
+		}
+	}
+	for _, opp := range prev {
+		if id := opp.ID(); !current[id] {
+			deltas = append(deltas, OpportunityDelta{ID: id, Previous: opp, Delta: -opp.Spread, Kind: RateRemoved})
+		}
+	}
+
+	sort.SliceStable(deltas, func(i, j int) bool {
+		a, b := deltas[i], deltas[j]
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		if a.Kind == RateChanged && a.Delta != b.Delta {
+			return a.Delta > b.Delta
+		}
+		if sa, sb := a.spread(), b.spread(); sa != sb {
+			return sa > sb
+		}
+		return a.ID < b.ID
+	})
+	return deltas
+}
+
+// spread returns the opportunity's latest known spread
+func (d OpportunityDelta) spread() float64 {
+	if d.Kind == RateRemoved {
+		return d.Previous.Spread
+	}
+	return d.Current.Spread
+}
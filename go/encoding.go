@@ -0,0 +1,94 @@
+package onlyfunding
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Decompressor wraps a compressed response body in a reader yielding the
+// decompressed bytes
+type Decompressor func(body io.Reader) (io.ReadCloser, error)
+
+// builtinDecompressors are the content encodings the SDK decodes without any
+// dependency. Brotli and zstd need third-party packages; plug them in with
+// WithDecompressor.
+var builtinDecompressors = map[string]Decompressor{
+	"gzip": func(body io.Reader) (io.ReadCloser, error) {
+		return gzip.NewReader(body)
+	},
+}
+
+// WithAcceptEncodings sets the content encodings the client asks for, in
+// order of preference, e.g. []string{"zstd", "br", "gzip"}. Encodings
+// without a decompressor are left out of the request, so the server falls
+// back to the ones the client can decode, or to identity. gzip is built in;
+// add others with WithDecompressor. Without this option Go's transport
+// negotiates gzip on its own.
+func WithAcceptEncodings(encodings []string) Option {
+	return func(c *Client) {
+		c.acceptEncodings = append([]string(nil), encodings...)
+	}
+}
+
+// WithDecompressor registers d for responses with the given Content-Encoding,
+// for use with WithAcceptEncodings. It keeps the SDK free of dependencies
+// while letting Brotli or zstd be decoded with the package of your choice:
+//
+//	onlyfunding.WithDecompressor("zstd", func(r io.Reader) (io.ReadCloser, error) {
+//		d, err := zstd.NewReader(r)
+//		if err != nil {
+//			return nil, err
+//		}
+//		return d.IOReadCloser(), nil
+//	})
+func WithDecompressor(encoding string, d Decompressor) Option {
+	return func(c *Client) {
+		if c.decompressors == nil {
+			c.decompressors = make(map[string]Decompressor)
+		}
+		c.decompressors[strings.ToLower(encoding)] = d
+	}
+}
+
+// decompressor returns the decompressor for encoding, if there is one
+func (c *Client) decompressor(encoding string) (Decompressor, bool) {
+	if d, ok := c.decompressors[encoding]; ok {
+		return d, true
+	}
+	d, ok := builtinDecompressors[encoding]
+	return d, ok
+}
+
+// acceptEncodingHeader returns the Accept-Encoding value for the configured
+// encodings the client can decode, or "" to leave negotiation to the
+// transport
+func (c *Client) acceptEncodingHeader() string {
+	var accepted []string
+	for _, encoding := range c.acceptEncodings {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+		if _, ok := c.decompressor(encoding); ok || encoding == "identity" {
+			accepted = append(accepted, encoding)
+		}
+	}
+	return strings.Join(accepted, ", ")
+}
+
+// decompressBody returns resp's body decoded per its Content-Encoding
+func (c *Client) decompressBody(resp *http.Response) (io.ReadCloser, error) {
+	encoding := strings.ToLower(strings.TrimSpace(resp.Header.Get("Content-Encoding")))
+	if encoding == "" || encoding == "identity" {
+		return resp.Body, nil
+	}
+	d, ok := c.decompressor(encoding)
+	if !ok {
+		return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+	}
+	body, err := d(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s response: %w", encoding, err)
+	}
+	return body, nil
+}
@@ -0,0 +1,53 @@
+package onlyfunding
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrUnexpectedContentType matches a *ContentTypeError with errors.Is
+var ErrUnexpectedContentType = errors.New("unexpected content type")
+
+// Lookup errors, wrapped with the exchange and symbol involved. Match them with
+// errors.Is to tell missing data apart from network or API failures.
+var (
+	// ErrRateNotFound means an exchange doesn't report a rate for a symbol
+	ErrRateNotFound = errors.New("rate not found")
+	// ErrSymbolNotFound means no exchange reports the requested symbol
+	ErrSymbolNotFound = errors.New("symbol not found on any exchange")
+	// ErrExchangeNotFound means the data has no rates for the requested
+	// exchange
+	ErrExchangeNotFound = errors.New("exchange not found")
+)
+
+// APIError is returned when the API responds with a status other than 200.
+// Use errors.As to inspect it; retries treat 5xx statuses as transient and
+// others as permanent.
+type APIError struct {
+	StatusCode int
+	// Body is the response body, usually an error message from the API
+	Body string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API request failed: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Body)
+}
+
+// ContentTypeError is returned when the API responds with a Content-Type the
+// SDK can't decode, typically an HTML error page served with a 200 by a CDN
+// or proxy in front of the API
+type ContentTypeError struct {
+	ContentType string
+	// Snippet is the start of the response body
+	Snippet string
+}
+
+func (e *ContentTypeError) Error() string {
+	return fmt.Sprintf("%s %q: %s", ErrUnexpectedContentType, e.ContentType, e.Snippet)
+}
+
+// Is reports whether target is ErrUnexpectedContentType
+func (e *ContentTypeError) Is(target error) bool {
+	return target == ErrUnexpectedContentType
+}
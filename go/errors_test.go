@@ -0,0 +1,116 @@
+package onlyfunding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLookupErrorsMatchSentinels(t *testing.T) {
+	c := newStubClient(t, ExampleFundingRatesData())
+	ctx := context.Background()
+
+	_, err := c.GetRate("bybit_1_perp", "DOGE")
+	if !errors.Is(err, ErrRateNotFound) {
+		t.Errorf("GetRate error = %v, want ErrRateNotFound", err)
+	}
+	_, err = c.GetRatesForSymbol("NOPE")
+	if !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("GetRatesForSymbol error = %v, want ErrSymbolNotFound", err)
+	}
+	_, err = c.RankExchangesForSymbol("NOPE")
+	if !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("RankExchangesForSymbol error = %v, want ErrSymbolNotFound", err)
+	}
+	_, err = c.GetExchangeRates(ctx, "nowhere")
+	if !errors.Is(err, ErrExchangeNotFound) {
+		t.Errorf("GetExchangeRates error = %v, want ErrExchangeNotFound", err)
+	}
+	_, _, _, err = SymbolDifference(ExampleFundingRatesData(), "okx_1_perp", "nowhere")
+	if !errors.Is(err, ErrExchangeNotFound) {
+		t.Errorf("SymbolDifference error = %v, want ErrExchangeNotFound", err)
+	}
+	data := ExampleFundingRatesData()
+	_, err = data.LookupSymbol("NOPE")
+	if !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("LookupSymbol error = %v, want ErrSymbolNotFound", err)
+	}
+	_, err = data.LookupExchange("nowhere")
+	if !errors.Is(err, ErrExchangeNotFound) {
+		t.Errorf("LookupExchange error = %v, want ErrExchangeNotFound", err)
+	}
+	_, err = data.OIRank("NOPE")
+	if !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("OIRank error = %v, want ErrSymbolNotFound", err)
+	}
+
+	for _, sentinel := range []error{ErrRateNotFound, ErrSymbolNotFound, ErrExchangeNotFound} {
+		var apiErr *APIError
+		if errors.As(sentinel, &apiErr) {
+			t.Errorf("%v matches APIError", sentinel)
+		}
+	}
+}
+
+func TestNon200IsAPIError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c := NewClientWithOptions(srv.URL, time.Second)
+
+	_, err := c.GetFundingRates()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("GetFundingRates error = %v, want an APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusServiceUnavailable || apiErr.Body != "maintenance\n" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if errors.Is(err, ErrRateNotFound) || errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("API failure %v matches a lookup sentinel", err)
+	}
+
+	_, err = c.PingWithLatency(context.Background())
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("PingWithLatency error = %v, want a 503 APIError", err)
+	}
+}
+
+// htmlServer answers with a 200 HTML page, as a CDN challenge would
+func htmlServer(t *testing.T) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte("<html><body>Just a moment...</body></html>"))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestHTMLResponseIsContentTypeError(t *testing.T) {
+	srv, hits := htmlServer(t)
+	c := NewClientWithOptions(srv.URL, time.Second, WithRetry(3, time.Millisecond))
+
+	_, err := c.GetFundingRates()
+	var ctErr *ContentTypeError
+	if !errors.As(err, &ctErr) {
+		t.Fatalf("GetFundingRates error = %v, want a ContentTypeError", err)
+	}
+	if !strings.HasPrefix(ctErr.ContentType, "text/html") || !strings.Contains(ctErr.Snippet, "Just a moment") {
+		t.Errorf("ContentTypeError = %+v", ctErr)
+	}
+	if !errors.Is(err, ErrUnexpectedContentType) {
+		t.Errorf("error = %v, want it to match ErrUnexpectedContentType", err)
+	}
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("server saw %d requests, want 1: a wrong content type is not retried", got)
+	}
+}
@@ -0,0 +1,133 @@
+package onlyfunding
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// WithServerSideFiltering tells the client the API accepts a symbols query
+// parameter, so GetFundingRatesFiltered only downloads the requested
+// symbols. Leave it off for servers that don't support the parameter; the
+// full matrix is then fetched and filtered locally.
+func WithServerSideFiltering(enabled bool) Option {
+	return func(c *Client) {
+		c.serverSideFiltering = enabled
+	}
+}
+
+// GetFundingRatesFiltered fetches funding rates for the given symbols only.
+// The result is filtered locally either way, so it is correct even if the
+// server ignores the symbols parameter.
+func (c *Client) GetFundingRatesFiltered(ctx context.Context, symbols []string) (*FundingRatesData, error) {
+	var query url.Values
+	if c.serverSideFiltering {
+		query = url.Values{"symbols": {strings.Join(symbols, ",")}}
+	}
+
+	data, err := c.fetchFundingRates(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	return data.filterSymbols(symbols), nil
+}
+
+// filterSymbols returns a copy of d restricted to symbols. Decode warnings
+// are per exchange, so all of them are kept.
+func (d *FundingRatesData) filterSymbols(symbols []string) *FundingRatesData {
+	keep := make(map[string]bool, len(symbols))
+	for _, symbol := range symbols {
+		keep[symbol] = true
+	}
+
+	filtered := &FundingRatesData{
+		Exchanges: ExchangesData{
+			ExchangeNames: append([]ExchangeInfo(nil), d.Exchanges.ExchangeNames...),
+			Exchanges:     append([]string(nil), d.Exchanges.Exchanges...),
+		},
+		FundingRates:  make(map[string]map[string]int, len(d.FundingRates)),
+		OIRankings:    make(map[string]string),
+		DefaultOIRank: d.DefaultOIRank,
+		Timestamp:     d.Timestamp,
+		warnings:      append([]DecodeWarning(nil), d.warnings...),
+	}
+	for _, symbol := range d.Symbols {
+		if keep[symbol] {
+			filtered.Symbols = append(filtered.Symbols, symbol)
+		}
+	}
+	for exchange, rates := range d.FundingRates {
+		kept := make(map[string]int)
+		for symbol, rate := range rates {
+			if keep[symbol] {
+				kept[symbol] = rate
+			}
+		}
+		filtered.FundingRates[exchange] = kept
+	}
+	for symbol, rank := range d.OIRankings {
+		if keep[symbol] {
+			filtered.OIRankings[symbol] = rank
+		}
+	}
+	return filtered
+}
+
+// GetExchangeRates fetches the rates of a single exchange. The result keeps
+// the common shape: FundingRates holds only that exchange, and Symbols and
+// the exchange list are narrowed to match. The API serves every exchange from
+// one endpoint, so this currently filters a full fetch locally. An exchange
+// without rates yields an error matching ErrExchangeNotFound.
+func (c *Client) GetExchangeRates(ctx context.Context, exchange string) (*FundingRatesData, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, ok := data.FundingRates[exchange]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrExchangeNotFound, exchange)
+	}
+	return data.filterExchange(exchange, rates), nil
+}
+
+// filterExchange returns a copy of d restricted to one exchange and the
+// symbols it reports
+func (d *FundingRatesData) filterExchange(exchange string, rates map[string]int) *FundingRatesData {
+	filtered := &FundingRatesData{
+		FundingRates:  map[string]map[string]int{exchange: make(map[string]int, len(rates))},
+		OIRankings:    make(map[string]string),
+		DefaultOIRank: d.DefaultOIRank,
+		Timestamp:     d.Timestamp,
+	}
+	for symbol, rate := range rates {
+		filtered.FundingRates[exchange][symbol] = rate
+	}
+	for _, symbol := range d.Symbols {
+		if _, ok := rates[symbol]; ok {
+			filtered.Symbols = append(filtered.Symbols, symbol)
+		}
+	}
+	for symbol, rank := range d.OIRankings {
+		if _, ok := rates[symbol]; ok {
+			filtered.OIRankings[symbol] = rank
+		}
+	}
+	for _, info := range d.Exchanges.ExchangeNames {
+		if info.Name == exchange {
+			filtered.Exchanges.ExchangeNames = append(filtered.Exchanges.ExchangeNames, info)
+		}
+	}
+	for _, name := range d.Exchanges.Exchanges {
+		if name == exchange {
+			filtered.Exchanges.Exchanges = append(filtered.Exchanges.Exchanges, name)
+		}
+	}
+	for _, w := range d.warnings {
+		if w.Exchange == exchange {
+			filtered.warnings = append(filtered.warnings, w)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,50 @@
+package onlyfunding
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestGetFundingRatesFilteredKeepsDecodeWarnings(t *testing.T) {
+	const body = `{
+		"symbols": ["BTC", "ETH"],
+		"exchanges": {"exchanges": ["binance_1_perp", "okx_1_perp"]},
+		"funding_rates": {
+			"binance_1_perp": {"BTC": 8, "ETH": -15},
+			"okx_1_perp": "unavailable"
+		}
+	}`
+	stub := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Header:     http.Header{"Content-Type": []string{contentTypeJSON}},
+			Body:       io.NopCloser(bytes.NewReader([]byte(body))),
+			Request:    req,
+		}, nil
+	})
+	c := NewClientWithOptions(DefaultBaseURL, time.Second, WithRoundTripper(stub))
+
+	data, err := c.GetFundingRatesFiltered(context.Background(), []string{"BTC"})
+	if err != nil {
+		t.Fatalf("GetFundingRatesFiltered error: %v", err)
+	}
+	warnings := data.DecodeWarnings()
+	if len(warnings) != 1 || warnings[0].Exchange != "okx_1_perp" {
+		t.Errorf("DecodeWarnings() = %v, want one for okx_1_perp", warnings)
+	}
+	if len(data.Symbols) != 1 || data.FundingRates["binance_1_perp"]["BTC"] != 8 {
+		t.Errorf("filtered data = %+v", data)
+	}
+
+	data, err = c.GetExchangeRates(context.Background(), "binance_1_perp")
+	if err != nil {
+		t.Fatalf("GetExchangeRates error: %v", err)
+	}
+	if warnings := data.DecodeWarnings(); len(warnings) != 0 {
+		t.Errorf("GetExchangeRates(binance_1_perp) kept warnings %v for other exchanges", warnings)
+	}
+}
@@ -0,0 +1,43 @@
+package onlyfunding
+
+import "encoding/json"
+
+// exampleFundingRatesJSON is a small but realistic API response
+const exampleFundingRatesJSON = `{
+  "symbols": ["BTC", "ETH", "SOL", "DOGE"],
+  "exchanges": {
+    "exchange_names": [
+      {"name": "binance_1_perp", "display": "BINANCE"},
+      {"name": "bybit_1_perp", "display": "BYBIT"},
+      {"name": "okx_1_perp", "display": "OKX"},
+      {"name": "hyperliquid_1_perp", "display": "HYPERLIQUID"}
+    ],
+    "exchanges": ["binance_1_perp", "bybit_1_perp", "okx_1_perp", "hyperliquid_1_perp"]
+  },
+  "funding_rates": {
+    "binance_1_perp": {"BTC": 8, "ETH": -15, "SOL": 25, "DOGE": 10},
+    "bybit_1_perp": {"BTC": 12, "ETH": -10, "SOL": 30},
+    "okx_1_perp": {"BTC": 5, "ETH": -20, "DOGE": 14},
+    "hyperliquid_1_perp": {"BTC": 40, "ETH": 8, "SOL": -12, "DOGE": 32}
+  },
+  "oi_rankings": {"BTC": "1", "ETH": "2", "SOL": "3", "DOGE": "9"},
+  "default_oi_rank": "500+",
+  "timestamp": "2024-01-15 14:30:25"
+}`
+
+// ExampleFundingRatesJSON returns a realistic raw API response with a few
+// exchanges and symbols, for use in tests and examples
+func ExampleFundingRatesJSON() []byte {
+	return []byte(exampleFundingRatesJSON)
+}
+
+// ExampleFundingRatesData returns ExampleFundingRatesJSON decoded. It is
+// built from the JSON on every call, so the two never drift apart and callers
+// are free to mutate the result.
+func ExampleFundingRatesData() *FundingRatesData {
+	var data FundingRatesData
+	if err := json.Unmarshal(ExampleFundingRatesJSON(), &data); err != nil {
+		panic("onlyfunding: invalid example fixture: " + err.Error())
+	}
+	return &data
+}
@@ -0,0 +1,63 @@
+package onlyfunding
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestExampleFixture(t *testing.T) {
+	data := ExampleFundingRatesData()
+
+	if warnings := data.DecodeWarnings(); len(warnings) != 0 {
+		t.Errorf("DecodeWarnings() = %v, want none", warnings)
+	}
+	if err := data.Validate(); err != nil {
+		t.Errorf("Validate() error: %v", err)
+	}
+
+	wantSymbols := []string{"BTC", "ETH", "SOL", "DOGE"}
+	if len(data.Symbols) != len(wantSymbols) {
+		t.Fatalf("Symbols = %v, want %v", data.Symbols, wantSymbols)
+	}
+	for i, symbol := range wantSymbols {
+		if data.Symbols[i] != symbol {
+			t.Errorf("Symbols[%d] = %s, want %s", i, data.Symbols[i], symbol)
+		}
+	}
+
+	wantExchanges := []string{"binance_1_perp", "bybit_1_perp", "okx_1_perp", "hyperliquid_1_perp"}
+	if len(data.Exchanges.Exchanges) != len(wantExchanges) {
+		t.Fatalf("Exchanges = %v, want %v", data.Exchanges.Exchanges, wantExchanges)
+	}
+	for i, exchange := range wantExchanges {
+		if data.Exchanges.Exchanges[i] != exchange {
+			t.Errorf("Exchanges[%d] = %s, want %s", i, data.Exchanges.Exchanges[i], exchange)
+		}
+		if _, ok := data.FundingRates[exchange]; !ok {
+			t.Errorf("no funding rates for %s", exchange)
+		}
+	}
+	if got := data.DisplayNames([]string{"okx_1_perp"})["okx_1_perp"]; got != "OKX" {
+		t.Errorf("display name of okx_1_perp = %q, want OKX", got)
+	}
+
+	if got := data.FundingRates["hyperliquid_1_perp"]["BTC"]; got != 40 {
+		t.Errorf("hyperliquid BTC rate = %d, want 40", got)
+	}
+	if _, ok := data.FundingRates["bybit_1_perp"]["DOGE"]; ok {
+		t.Error("bybit_1_perp unexpectedly lists DOGE")
+	}
+}
+
+func TestExampleFixtureDecodesThroughClient(t *testing.T) {
+	var data FundingRatesData
+	if err := decodeJSON(bytes.NewReader(ExampleFundingRatesJSON()), &data); err != nil {
+		t.Fatalf("decodeJSON error: %v", err)
+	}
+	if data.Timestamp != "2024-01-15 14:30:25" {
+		t.Errorf("Timestamp = %q", data.Timestamp)
+	}
+	if rank, err := data.EffectiveOIRank("BTC"); err != nil || rank != 1 {
+		t.Errorf("EffectiveOIRank(BTC) = %d, %v; want 1", rank, err)
+	}
+}
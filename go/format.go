@@ -0,0 +1,171 @@
+package onlyfunding
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxTableNameWidth caps the exchange column so long display names don't
+// break the table alignment
+const maxTableNameWidth = 20
+
+// FormatOption configures the formatting and export helpers
+type FormatOption func(*formatConfig)
+
+// formatConfig holds the settings FormatOptions apply. precision is the
+// number of decimal places to render, or -1 for each formatter's default.
+// feeSpread is the per-interval spread taken off for trading fees.
+type formatConfig struct {
+	precision int
+	feeSpread float64
+}
+
+// WithDisplayPrecision rounds rendered numbers to places decimal places, in
+// the unit each formatter displays: percent in FormatSymbolTable, decimals in
+// WriteOpportunitiesCSV. Only the output is rounded; the values themselves
+// keep full precision.
+func WithDisplayPrecision(places int) FormatOption {
+	return func(cfg *formatConfig) {
+		if places >= 0 {
+			cfg.precision = places
+		}
+	}
+}
+
+// WithTradingFees makes WriteOpportunitiesCSV report net_spread after
+// round-trip trading fees: Spread minus BreakEvenSpread(longFee, shortFee,
+// intervals), the fees amortized over a holding period of intervals funding
+// intervals. Fees are decimal rates per trade, as for BreakEvenSpread. A
+// non-positive intervals is ignored.
+func WithTradingFees(longFee, shortFee float64, intervals int) FormatOption {
+	return func(cfg *formatConfig) {
+		if intervals > 0 {
+			cfg.feeSpread = BreakEvenSpread(longFee, shortFee, intervals)
+		}
+	}
+}
+
+// newFormatConfig applies opts over the defaults
+func newFormatConfig(opts []FormatOption) formatConfig {
+	cfg := formatConfig{precision: -1}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// FormatSymbolTable renders an aligned ASCII table of every exchange's rate
+// for symbol, highest rate first. Rates are shown as percentages with four
+// decimal places unless WithDisplayPrecision says otherwise.
+func (d *FundingRatesData) FormatSymbolTable(symbol string, opts ...FormatOption) string {
+	cfg := newFormatConfig(opts)
+	places := 4
+	if cfg.precision >= 0 {
+		places = cfg.precision
+	}
+
+	rates := d.symbolRates(symbol)
+
+	exchanges := make([]string, 0, len(rates))
+	for exchange := range rates {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Slice(exchanges, func(i, j int) bool {
+		if rates[exchanges[i]] != rates[exchanges[j]] {
+			return rates[exchanges[i]] > rates[exchanges[j]]
+		}
+		return exchanges[i] < exchanges[j]
+	})
+
+	names := make([]string, len(exchanges))
+	cells := make([]string, len(exchanges))
+	nameWidth, rateWidth := len("Exchange"), len("Rate")
+	for i, exchange := range exchanges {
+		names[i] = truncate(d.displayName(exchange), maxTableNameWidth)
+		cells[i] = fmt.Sprintf("%.*f%%", places, float64(rates[exchange])/100.0)
+		if n := utf8.RuneCountInString(names[i]); n > nameWidth {
+			nameWidth = n
+		}
+		if len(cells[i]) > rateWidth {
+			rateWidth = len(cells[i])
+		}
+	}
+
+	border := "+" + strings.Repeat("-", nameWidth+2) + "+" + strings.Repeat("-", rateWidth+2) + "+\n"
+
+	var b strings.Builder
+	b.WriteString(border)
+	fmt.Fprintf(&b, "| %s | %*s |\n", padRight("Exchange", nameWidth), rateWidth, "Rate")
+	b.WriteString(border)
+	for i := range exchanges {
+		fmt.Fprintf(&b, "| %s | %*s |\n", padRight(names[i], nameWidth), rateWidth, cells[i])
+	}
+	b.WriteString(border)
+	return b.String()
+}
+
+// truncate shortens s to at most width runes, marking the cut with "...", so
+// multi-byte display names are never split mid-character
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 3 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-3]) + "..."
+}
+
+// padRight pads s with spaces to width runes
+func padRight(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// opportunityCSVHeader lists the columns written by WriteOpportunitiesCSV
+var opportunityCSVHeader = []string{
+	"symbol", "long_exchange", "short_exchange", "long_rate", "short_rate", "spread", "net_spread",
+}
+
+// WriteOpportunitiesCSV writes opportunities as CSV with a header row, one
+// trade per line. Rates and spreads are decimals. net_spread is
+// NetFundingYield net of the fees given with WithTradingFees; without fees it
+// equals spread for scan results. Numbers are written at full precision
+// unless WithDisplayPrecision is given.
+func WriteOpportunitiesCSV(w io.Writer, opps []ArbitrageOpportunity, opts ...FormatOption) error {
+	cfg := newFormatConfig(opts)
+	cw := csv.NewWriter(w)
+	if err := cw.Write(opportunityCSVHeader); err != nil {
+		return err
+	}
+	for _, opp := range opps {
+		record := []string{
+			opp.Symbol,
+			opp.LongExchange,
+			opp.ShortExchange,
+			formatDecimal(opp.LongRate(), cfg.precision),
+			formatDecimal(opp.ShortRate(), cfg.precision),
+			formatDecimal(opp.Spread, cfg.precision),
+			formatDecimal(opp.NetFundingYield()-cfg.feeSpread, cfg.precision),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatDecimal formats v in plain decimal notation, never exponent form,
+// rounded to places decimal places or with as many as needed if places is -1
+func formatDecimal(v float64, places int) string {
+	return strconv.FormatFloat(v, 'f', places, 64)
+}
@@ -0,0 +1,123 @@
+package onlyfunding
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// TestExportsAreDeterministic renders each export twice from independently
+// decoded data, so map iteration order differs between the runs, and
+// compares the bytes
+func TestExportsAreDeterministic(t *testing.T) {
+	exports := []struct {
+		name   string
+		render func(t *testing.T) []byte
+	}{
+		{"WriteOpportunitiesCSV", func(t *testing.T) []byte {
+			c := newStubClient(t, tiedData(), WithIncludeZeroSpread())
+			opps, err := c.FindAllArbitrageOpportunities(0)
+			if err != nil {
+				t.Fatalf("FindAllArbitrageOpportunities error: %v", err)
+			}
+			var buf bytes.Buffer
+			if err := WriteOpportunitiesCSV(&buf, opps); err != nil {
+				t.Fatalf("WriteOpportunitiesCSV error: %v", err)
+			}
+			return buf.Bytes()
+		}},
+		{"FormatSymbolTable", func(t *testing.T) []byte {
+			return []byte(tiedData().FormatSymbolTable("TIE"))
+		}},
+		{"WriteOpenMetrics", func(t *testing.T) []byte {
+			var buf bytes.Buffer
+			if err := tiedData().WriteOpenMetrics(&buf); err != nil {
+				t.Fatalf("WriteOpenMetrics error: %v", err)
+			}
+			return buf.Bytes()
+		}},
+	}
+	for _, export := range exports {
+		t.Run(export.name, func(t *testing.T) {
+			first := export.render(t)
+			if len(first) == 0 {
+				t.Fatal("empty export")
+			}
+			for i := 0; i < 10; i++ {
+				if again := export.render(t); !bytes.Equal(first, again) {
+					t.Fatalf("run %d differs:\n%s\nvs\n%s", i, first, again)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteOpportunitiesCSVNetSpread(t *testing.T) {
+	opps := []ArbitrageOpportunity{{
+		Symbol:        "BTC",
+		Exchange1:     "binance_1_perp",
+		Rate1:         0.0008,
+		Exchange2:     "hyperliquid_1_perp",
+		Rate2:         0.004,
+		Spread:        0.0032,
+		LongExchange:  "binance_1_perp",
+		ShortExchange: "hyperliquid_1_perp",
+	}}
+
+	tests := []struct {
+		name string
+		opts []FormatOption
+		want string
+	}{
+		{"no fees", nil, "BTC,binance_1_perp,hyperliquid_1_perp,0.0008,0.0040,0.0032,0.0032"},
+		// 2 * (0.0005 + 0.0003) / 4 = 0.0004 per interval
+		{"fees", []FormatOption{WithTradingFees(0.0005, 0.0003, 4)}, "BTC,binance_1_perp,hyperliquid_1_perp,0.0008,0.0040,0.0032,0.0028"},
+		{"no intervals", []FormatOption{WithTradingFees(0.0005, 0.0003, 0)}, "BTC,binance_1_perp,hyperliquid_1_perp,0.0008,0.0040,0.0032,0.0032"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			opts := append([]FormatOption{WithDisplayPrecision(4)}, tt.opts...)
+			if err := WriteOpportunitiesCSV(&buf, opps, opts...); err != nil {
+				t.Fatalf("WriteOpportunitiesCSV error: %v", err)
+			}
+			want := "symbol,long_exchange,short_exchange,long_rate,short_rate,spread,net_spread\n" + tt.want + "\n"
+			if got := buf.String(); got != want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, want)
+			}
+		})
+	}
+}
+
+func TestFormatSymbolTableMultiByteNames(t *testing.T) {
+	data := &FundingRatesData{
+		Symbols: []string{"BTC"},
+		Exchanges: ExchangesData{
+			ExchangeNames: []ExchangeInfo{
+				{Name: "a_1_perp", Display: "Börse"},
+				{Name: "b_1_perp", Display: "取引所取引所取引所取引所取引所取引所取引所"},
+			},
+			Exchanges: []string{"a_1_perp", "b_1_perp"},
+		},
+		FundingRates: map[string]map[string]int{
+			"a_1_perp": {"BTC": 10},
+			"b_1_perp": {"BTC": 5},
+		},
+	}
+
+	table := data.FormatSymbolTable("BTC")
+	lines := strings.Split(strings.TrimSuffix(table, "\n"), "\n")
+	width := utf8.RuneCountInString(lines[0])
+	for _, line := range lines {
+		if !utf8.ValidString(line) {
+			t.Fatalf("invalid UTF-8 in %q", line)
+		}
+		if got := utf8.RuneCountInString(line); got != width {
+			t.Errorf("line %q is %d runes wide, want %d:\n%s", line, got, width, table)
+		}
+	}
+	if !strings.Contains(table, "取引所取引所取引所取引所取引所取引...") {
+		t.Errorf("long name not truncated to %d runes:\n%s", maxTableNameWidth, table)
+	}
+}
@@ -1,11 +1,25 @@
 // Package onlyfunding provides a Go client for onlyfunding.fun funding rates API
+//
+// Units: the API sends each rate as an integer number of basis points, kept
+// as-is in FundingRatesData.FundingRates. Every float rate and spread the SDK
+// returns is a decimal fraction, the raw value divided by 10000. A raw 25 is
+// therefore 25 bps, 0.0025 as a decimal, or 0.25%. Multiply a decimal by 100
+// for percent or by 10000 for basis points.
 package onlyfunding
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
+	"hash/fnv"
 	"io"
+	"math"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,6 +28,9 @@ const (
 	DefaultTimeout = 30 * time.Second
 )
 
+// maxSnippetSize caps how much of an unexpected response body is kept in errors
+const maxSnippetSize = 256
+
 // ExchangeInfo represents exchange information
 type ExchangeInfo struct {
 	Name    string `json:"name"`
@@ -26,26 +43,95 @@ type ExchangesData struct {
 	Exchanges     []string       `json:"exchanges"`
 }
 
-// FundingRatesData represents the API response
+// FundingRatesData represents the API response. OIRankings maps symbols to
+// their open interest rank, 1 being the largest market. DefaultOIRank, such as
+// "500+", is the rank of every symbol missing from OIRankings: it is a rank,
+// not a symbol name. Use OIRank, DefaultOIRankValue or EffectiveOIRank to read
+// ranks as integers.
 type FundingRatesData struct {
-	Symbols       []string                          `json:"symbols"`
-	Exchanges     ExchangesData                     `json:"exchanges"`
-	FundingRates  map[string]map[string]int         `json:"funding_rates"`
-	OIRankings    map[string]string                 `json:"oi_rankings"`
-	DefaultOIRank string                           `json:"default_oi_rank"`
-	Timestamp     string                           `json:"timestamp"`
+	Symbols       []string                  `json:"symbols"`
+	Exchanges     ExchangesData             `json:"exchanges"`
+	FundingRates  map[string]map[string]int `json:"funding_rates"`
+	OIRankings    map[string]string         `json:"oi_rankings"`
+	DefaultOIRank string                    `json:"default_oi_rank"`
+	Timestamp     string                    `json:"timestamp"`
+
+	warnings []DecodeWarning
 }
 
-// ArbitrageOpportunity represents an arbitrage opportunity
+// ArbitrageOpportunity represents an arbitrage opportunity. Rate1, Rate2 and
+// Spread are decimals; Rate1BP, Rate2BP and SpreadBP hold the same values as
+// the exact integer basis points the API sent.
 type ArbitrageOpportunity struct {
-	Symbol       string
-	Exchange1    string
-	Rate1        float64
-	Exchange2    string
-	Rate2        float64
-	Spread       float64
-	LongExchange string
+	Symbol        string
+	Exchange1     string
+	Rate1         float64
+	Exchange2     string
+	Rate2         float64
+	Spread        float64
+	LongExchange  string
 	ShortExchange string
+	Rate1BP       int
+	Rate2BP       int
+	SpreadBP      int
+}
+
+// LongRate returns the rate of the exchange to go long on
+func (o ArbitrageOpportunity) LongRate() float64 {
+	if o.LongExchange == o.Exchange1 {
+		return o.Rate1
+	}
+	return o.Rate2
+}
+
+// ShortRate returns the rate of the exchange to go short on
+func (o ArbitrageOpportunity) ShortRate() float64 {
+	if o.ShortExchange == o.Exchange1 {
+		return o.Rate1
+	}
+	return o.Rate2
+}
+
+// SymbolRate is one exchange's funding rate for a symbol
+type SymbolRate struct {
+	Exchange string
+	Symbol   string
+	Rate     float64
+}
+
+// BasisPoints returns Rate in basis points
+func (r SymbolRate) BasisPoints() float64 {
+	return r.Rate * 10000.0
+}
+
+// ID returns a stable identifier for the opportunity derived from its symbol
+// and exchange pair, ignoring rates and pair order, so the same opportunity
+// can be tracked across polls
+func (o ArbitrageOpportunity) ID() string {
+	pair := pairKey(o.Exchange1, o.Exchange2)
+	h := fnv.New64a()
+	h.Write([]byte(o.Symbol + "\x00" + pair[0] + "\x00" + pair[1]))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// PairKey returns the opportunity's exchange pair as "a|b", ordered so the
+// key is the same whichever exchange is Exchange1
+func (o ArbitrageOpportunity) PairKey() string {
+	pair := pairKey(o.Exchange1, o.Exchange2)
+	return pair[0] + "|" + pair[1]
+}
+
+// NetFundingYield returns the funding collected per interval, per unit of
+// notional, by shorting ShortExchange and longing LongExchange. Scans always
+// long the lower rate, so for their results it equals Spread; it differs only
+// for opportunities built or edited by hand, where it can be negative.
+func (o ArbitrageOpportunity) NetFundingYield() float64 {
+	return o.ShortRate() - o.LongRate()
+}
+
+// SpreadBasisPoints returns Spread in basis points
+func (o ArbitrageOpportunity) SpreadBasisPoints() float64 {
+	return o.Spread * 10000.0
 }
 
 // Client is the main client for interacting with the onlyfunding API
@@ -53,62 +139,251 @@ type Client struct {
 	baseURL string
 	timeout time.Duration
 	client  *http.Client
+
+	maxIdleConns    int
+	idleConnTimeout time.Duration
+	keepAlive       time.Duration
+	roundTripper    http.RoundTripper
+	httpClient      *http.Client
+	httpVersion     httpVersion
+
+	acceptContentType string
+	acceptEncodings   []string
+	jsonDecoder       func(r io.Reader, v interface{}) error
+	decompressors     map[string]Decompressor
+
+	maxAttempts    int
+	baseDelay      time.Duration
+	retryHook      func(attempt int, err error, delay time.Duration)
+	retryOnTimeout bool
+	attemptTimeout time.Duration
+	rand           *rand.Rand
+	randMu         sync.Mutex
+
+	intervals map[string]time.Duration
+	schedules map[string]FundingSchedule
+
+	scan scanConfig
+
+	middleware []Middleware
+
+	jsonLogger *jsonLogger
+	recorder   *Recorder
+
+	serverSideFiltering bool
+
+	coalesce bool
+	flight   flightGroup
+
+	cacheTTL time.Duration
+	cache    rateCache
+
+	lastResponseMu sync.Mutex
+	rateLimit      RateLimitState
+	lastHeaders    http.Header
+
+	router *latencyRouter
+
+	skipReadiness bool
 }
 
 // NewClient creates a new onlyfunding client with default settings
-func NewClient() *Client {
-	return &Client{
-		baseURL: DefaultBaseURL,
-		timeout: DefaultTimeout,
-		client: &http.Client{
-			Timeout: DefaultTimeout,
-		},
-	}
+func NewClient(opts ...Option) *Client {
+	return NewClientWithOptions(DefaultBaseURL, DefaultTimeout, opts...)
 }
 
 // NewClientWithOptions creates a new client with custom options
-func NewClientWithOptions(baseURL string, timeout time.Duration) *Client {
-	return &Client{
-		baseURL: baseURL,
-		timeout: timeout,
-		client: &http.Client{
-			Timeout: timeout,
-		},
+func NewClientWithOptions(baseURL string, timeout time.Duration, opts ...Option) *Client {
+	c := &Client{
+		baseURL:           baseURL,
+		timeout:           timeout,
+		acceptContentType: contentTypeJSON,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.scan.interval = c.Interval
+	if c.rand == nil {
+		c.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	if c.httpClient != nil {
+		c.timeout = c.httpClient.Timeout
 	}
+	base := c.baseTransport()
+	c.client = c.newHTTPClient(c.chain(base))
+	if c.router != nil {
+		c.router.start(c, c.newHTTPClient(c.probeChain(base)))
+	}
+	return c
 }
 
 // GetFundingRates fetches current funding rates from all exchanges
 func (c *Client) GetFundingRates() (*FundingRatesData, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/funding", c.baseURL), nil)
+	return c.GetFundingRatesContext(context.Background())
+}
+
+// GetFundingRatesContext is GetFundingRates under ctx. The client timeout
+// still bounds the request when ctx has a later deadline or none. If ctx is
+// canceled or expires the error wraps ctx.Err(), so errors.Is(err,
+// context.Canceled) reports it. Every method that fetches rates has a Context
+// variant with the same behavior.
+func (c *Client) GetFundingRatesContext(ctx context.Context) (*FundingRatesData, error) {
+	return c.fetchFundingRates(ctx, nil)
+}
+
+// fetchFundingRates fetches funding rates under ctx, with optional query
+// parameters, serving unfiltered requests from the cache while it is fresh
+func (c *Client) fetchFundingRates(ctx context.Context, query url.Values) (*FundingRatesData, error) {
+	if len(query) == 0 && c.cacheTTL > 0 {
+		if data, ok := c.cache.get(c.cacheTTL); ok {
+			return data, nil
+		}
+	}
+	return c.fetchUncached(ctx, query)
+}
+
+// fetchUncached fetches funding rates over the network, sharing in-flight
+// requests when coalescing is enabled, and caches unfiltered results
+func (c *Client) fetchUncached(ctx context.Context, query url.Values) (*FundingRatesData, error) {
+	var data *FundingRatesData
+	var err error
+	if c.coalesce {
+		var shared bool
+		data, shared, err = c.flight.do(query.Encode(), func() (*FundingRatesData, error) {
+			return c.requestFundingRates(ctx, query)
+		})
+		if err == nil && shared {
+			data = data.clone()
+		}
+	} else {
+		data, err = c.requestFundingRates(ctx, query)
+	}
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, err
 	}
 
-	req.Header.Set("Accept", "application/json")
-	req.Header.Set("User-Agent", "onlyfunding-Go-SDK/1.0.0")
+	if len(query) == 0 && c.cacheTTL > 0 {
+		c.cache.set(data)
+	}
+	return data, nil
+}
+
+// requestFundingRates performs the funding rates request, retrying transient
+// failures when retries are enabled. The correlation ID is fixed before the
+// first attempt so every retry of the call carries the same one.
+func (c *Client) requestFundingRates(ctx context.Context, query url.Values) (*FundingRatesData, error) {
+	if _, ok := CorrelationIDFromContext(ctx); !ok {
+		ctx = WithCorrelationID(ctx, correlationID(ctx))
+	}
+	for attempt := 1; ; attempt++ {
+		data, err := c.attemptFundingRates(withAttempt(ctx, attempt), query)
+		if err == nil {
+			return data, nil
+		}
+		if attempt >= c.maxAttempts || !c.retryable(err) || ctx.Err() != nil {
+			if attempt > 1 {
+				return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+			}
+			return nil, err
+		}
+
+		delay := c.backoff(attempt)
+		delay = delay/2 + c.jitter(delay-delay/2)
+		if c.retryHook != nil {
+			c.retryHook(attempt, err, delay)
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return nil, fmt.Errorf("giving up after %d attempts: %w", attempt, err)
+		}
+	}
+}
+
+// attemptFundingRates performs a single funding rates request
+func (c *Client) attemptFundingRates(ctx context.Context, query url.Values) (*FundingRatesData, error) {
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+	ctx, cancelAttempt := c.attemptContext(ctx)
+	defer cancelAttempt()
+
+	base := c.endpoint()
+	endpoint := fmt.Sprintf("%s/funding", base)
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
+		if c.router != nil && ctx.Err() == nil {
+			c.router.markFailed(base)
+		}
 		return nil, fmt.Errorf("failed to fetch funding rates: %w", err)
 	}
 	defer resp.Body.Close()
+	c.recordResponse(resp)
+	if c.router != nil && resp.StatusCode >= 500 {
+		c.router.markFailed(base)
+	}
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("API request failed: %d %s: %s", resp.StatusCode, resp.Status, string(body))
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	respBody, err := c.decompressBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	defer respBody.Close()
+
+	contentType := resp.Header.Get("Content-Type")
+	decode, ok := c.decoderFor(contentType)
+	if !ok {
+		snippet, _ := io.ReadAll(io.LimitReader(respBody, maxSnippetSize))
+		return nil, &ContentTypeError{ContentType: contentType, Snippet: strings.TrimSpace(string(snippet))}
 	}
 
+	body := &trackingReader{r: respBody}
 	var data FundingRatesData
-	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+	if err := decode(body, &data); err != nil {
+		if body.err != nil || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, &readError{err: fmt.Errorf("failed to read response: %w", err)}
+		}
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
+	if c.recorder != nil {
+		c.recorder.Record(&data)
+	}
+
 	return &data, nil
 }
 
-// GetRate gets funding rate for a specific exchange and symbol
+// requestContext bounds ctx by the client timeout, so a request's deadline is
+// the earlier of the caller's deadline and now plus the timeout
+func (c *Client) requestContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return ctx, func() {}
+	}
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= c.timeout {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// GetRate gets funding rate for a specific exchange and symbol.
+// If the exchange doesn't report the symbol the error matches ErrRateNotFound.
 func (c *Client) GetRate(exchange, symbol string) (float64, error) {
-	data, err := c.GetFundingRates()
+	return c.GetRateContext(context.Background(), exchange, symbol)
+}
+
+// GetRateContext is GetRate under ctx
+func (c *Client) GetRateContext(ctx context.Context, exchange, symbol string) (float64, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
 	if err != nil {
 		return 0, err
 	}
@@ -119,26 +394,213 @@ func (c *Client) GetRate(exchange, symbol string) (float64, error) {
 		}
 	}
 
-	return 0, fmt.Errorf("rate not found for %s on %s", symbol, exchange)
+	return 0, fmt.Errorf("%w for %s on %s", ErrRateNotFound, symbol, exchange)
+}
+
+// RatePair identifies one exchange's rate for a symbol
+type RatePair struct {
+	Exchange string
+	Symbol   string
+}
+
+// GetRates looks up several rates from a single fetch. Duplicate pairs are
+// resolved once. Pairs without a rate are left out of the map and listed in
+// the returned error, alongside the rates that were found.
+func (c *Client) GetRates(pairs []RatePair) (map[RatePair]float64, error) {
+	return c.GetRatesContext(context.Background(), pairs)
+}
+
+// GetRatesContext is GetRates under ctx
+func (c *Client) GetRatesContext(ctx context.Context, pairs []RatePair) (map[RatePair]float64, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[RatePair]float64, len(pairs))
+	seen := make(map[RatePair]bool, len(pairs))
+	var missing []string
+	for _, pair := range pairs {
+		if seen[pair] {
+			continue
+		}
+		seen[pair] = true
+		if rate, ok := data.FundingRates[pair.Exchange][pair.Symbol]; ok {
+			rates[pair] = float64(rate) / 10000.0
+		} else {
+			missing = append(missing, pair.Symbol+" on "+pair.Exchange)
+		}
+	}
+	if len(missing) > 0 {
+		return rates, fmt.Errorf("%w for %s", ErrRateNotFound, strings.Join(missing, ", "))
+	}
+	return rates, nil
+}
+
+// GetRatesForSymbol returns every exchange's rate for symbol from a single
+// fetch, keyed by exchange, as decimals like GetRate. If no exchange reports
+// the symbol the error matches ErrSymbolNotFound.
+func (c *Client) GetRatesForSymbol(symbol string) (map[string]float64, error) {
+	return c.GetRatesForSymbolContext(context.Background(), symbol)
+}
+
+// GetRatesForSymbolContext is GetRatesForSymbol under ctx
+func (c *Client) GetRatesForSymbolContext(ctx context.Context, symbol string) (map[string]float64, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := data.symbolRates(symbol)
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	rates := make(map[string]float64, len(raw))
+	for exchange, rate := range raw {
+		rates[exchange] = float64(rate) / 10000.0
+	}
+	return rates, nil
+}
+
+// GetExchanges returns the exchanges the API covers, with their display names
+func (c *Client) GetExchanges() ([]ExchangeInfo, error) {
+	return c.GetExchangesContext(context.Background())
+}
+
+// GetExchangesContext is GetExchanges under ctx
+func (c *Client) GetExchangesContext(ctx context.Context) ([]ExchangeInfo, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return data.Exchanges.ExchangeNames, nil
+}
+
+// GetSymbols returns the symbols the API covers
+func (c *Client) GetSymbols() ([]string, error) {
+	return c.GetSymbolsContext(context.Background())
+}
+
+// GetSymbolsContext is GetSymbols under ctx
+func (c *Client) GetSymbolsContext(ctx context.Context) ([]string, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return data.Symbols, nil
+}
+
+// RankExchangesForSymbol returns every exchange's rate for a symbol, highest
+// first
+func (c *Client) RankExchangesForSymbol(symbol string) ([]SymbolRate, error) {
+	return c.RankExchangesForSymbolContext(context.Background(), symbol)
+}
+
+// RankExchangesForSymbolContext is RankExchangesForSymbol under ctx
+func (c *Client) RankExchangesForSymbolContext(ctx context.Context, symbol string) ([]SymbolRate, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	ranked := data.rankedRates(symbol)
+	if len(ranked) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrSymbolNotFound, symbol)
+	}
+	return ranked, nil
+}
+
+// rankedRates returns the rates reported for symbol, highest first with ties
+// ordered by exchange
+func (d *FundingRatesData) rankedRates(symbol string) []SymbolRate {
+	rates := d.symbolRates(symbol)
+	ranked := make([]SymbolRate, 0, len(rates))
+	for exchange, rate := range rates {
+		ranked = append(ranked, SymbolRate{
+			Exchange: exchange,
+			Symbol:   symbol,
+			Rate:     float64(rate) / 10000.0,
+		})
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].Rate != ranked[j].Rate {
+			return ranked[i].Rate > ranked[j].Rate
+		}
+		return ranked[i].Exchange < ranked[j].Exchange
+	})
+	return ranked
 }
 
 // FindArbitrageOpportunities finds arbitrage opportunities for a symbol
 func (c *Client) FindArbitrageOpportunities(symbol string, minSpread float64) ([]ArbitrageOpportunity, error) {
-	data, err := c.GetFundingRates()
+	return c.FindArbitrageOpportunitiesContext(context.Background(), symbol, minSpread)
+}
+
+// FindArbitrageOpportunitiesContext is FindArbitrageOpportunities under ctx
+func (c *Client) FindArbitrageOpportunitiesContext(ctx context.Context, symbol string, minSpread float64) ([]ArbitrageOpportunity, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// Collect all rates for the symbol
-	rates := make(map[string]int)
-	for exchange, symbols := range data.FundingRates {
-		if rate, ok := symbols[symbol]; ok {
-			rates[exchange] = rate
-		}
+	return data.arbitrageOpportunities(symbol, minSpread, &c.scan), nil
+}
+
+// FindArbitrageOpportunitiesBP finds arbitrage opportunities for a symbol
+// with a spread of at least minSpreadBP basis points. Spreads are computed and
+// compared on the API's integer basis points, so the threshold is exact and
+// floats only appear in the returned values. This holds under
+// WithHourlyNormalization too: the threshold applies to SpreadBP, the raw
+// spread, while Spread and the ranking are per hour.
+func (c *Client) FindArbitrageOpportunitiesBP(symbol string, minSpreadBP int) ([]ArbitrageOpportunity, error) {
+	return c.FindArbitrageOpportunitiesBPContext(context.Background(), symbol, minSpreadBP)
+}
+
+// FindArbitrageOpportunitiesBPContext is FindArbitrageOpportunitiesBP under ctx
+func (c *Client) FindArbitrageOpportunitiesBPContext(ctx context.Context, symbol string, minSpreadBP int) ([]ArbitrageOpportunity, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return nil, err
 	}
 
-	if len(rates) < 2 {
-		return []ArbitrageOpportunity{}, nil
+	return data.arbitrageOpportunitiesBP(symbol, minSpreadBP, &c.scan), nil
+}
+
+// arbitrageOpportunities finds every exchange pair for symbol allowed by cfg
+// whose spread is at least minSpread, widest first. Rates are decoded as
+// integer basis points, so they can't be NaN or infinite; a NaN minSpread
+// matches nothing.
+func (d *FundingRatesData) arbitrageOpportunities(symbol string, minSpread float64, cfg *scanConfig) []ArbitrageOpportunity {
+	if math.IsNaN(minSpread) {
+		return []ArbitrageOpportunity{}
+	}
+	return d.pairOpportunities(symbol, cfg, func(spreadBP int, quotedBP float64) bool {
+		return quotedBP/10000.0 >= minSpread
+	})
+}
+
+// arbitrageOpportunitiesBP is arbitrageOpportunities with an integer basis
+// point threshold on the raw spread
+func (d *FundingRatesData) arbitrageOpportunitiesBP(symbol string, minSpreadBP int, cfg *scanConfig) []ArbitrageOpportunity {
+	return d.pairOpportunities(symbol, cfg, func(spreadBP int, quotedBP float64) bool {
+		return spreadBP >= minSpreadBP
+	})
+}
+
+// pairOpportunities builds an opportunity for every exchange pair for symbol
+// allowed by cfg whose spread passes keep, widest first. keep is given the
+// exact difference of the raw integer rates and the spread between the quoted
+// rates, both in basis points; they are equal without hourly normalization.
+func (d *FundingRatesData) pairOpportunities(symbol string, cfg *scanConfig, keep func(spreadBP int, quotedBP float64) bool) []ArbitrageOpportunity {
+	if !cfg.allowsSymbol(d, symbol) {
+		return []ArbitrageOpportunity{}
+	}
+
+	// Collect all rates for the symbol
+	rates := cfg.reconcile(d, d.symbolRates(symbol))
+
+	if len(rates) < cfg.requiredExchanges() {
+		return []ArbitrageOpportunity{}
 	}
 
 	var opportunities []ArbitrageOpportunity
@@ -146,18 +608,35 @@ func (c *Client) FindArbitrageOpportunities(symbol string, minSpread float64) ([
 	for exchange := range rates {
 		exchanges = append(exchanges, exchange)
 	}
+	// Pair in a fixed order so Exchange1/Exchange2 and the order of equal
+	// spreads don't depend on map iteration
+	sort.Strings(exchanges)
 
 	// Find all pairs
 	for i, exchange1 := range exchanges {
 		for _, exchange2 := range exchanges[i+1:] {
+			if !cfg.allowsPair(exchange1, exchange2) {
+				continue
+			}
+
 			rate1 := rates[exchange1]
 			rate2 := rates[exchange2]
-			spread := abs(float64(rate1-rate2)) / 10000.0
+			spreadBP := rate1 - rate2
+			if spreadBP < 0 {
+				spreadBP = -spreadBP
+			}
 
-			if spread >= minSpread {
+			// quoted rates, in basis points per the compared period
+			quoted1, quoted2 := cfg.quote(exchange1, rate1), cfg.quote(exchange2, rate2)
+			quotedSpread := abs(quoted1 - quoted2)
+			if quotedSpread == 0 && !cfg.includeZeroSpread {
+				continue
+			}
+
+			if keep(spreadBP, quotedSpread) {
 				longExchange := exchange1
 				shortExchange := exchange2
-				if rate1 > rate2 {
+				if quoted1 > quoted2 {
 					longExchange = exchange2
 					shortExchange = exchange1
 				}
@@ -165,27 +644,140 @@ func (c *Client) FindArbitrageOpportunities(symbol string, minSpread float64) ([
 				opportunities = append(opportunities, ArbitrageOpportunity{
 					Symbol:        symbol,
 					Exchange1:     exchange1,
-					Rate1:         float64(rate1) / 10000.0,
+					Rate1:         quoted1 / 10000.0,
 					Exchange2:     exchange2,
-					Rate2:         float64(rate2) / 10000.0,
-					Spread:        spread,
+					Rate2:         quoted2 / 10000.0,
+					Spread:        quotedSpread / 10000.0,
 					LongExchange:  longExchange,
 					ShortExchange: shortExchange,
+					Rate1BP:       rate1,
+					Rate2BP:       rate2,
+					SpreadBP:      spreadBP,
 				})
 			}
 		}
 	}
 
-	// Sort by spread descending
-	for i := 0; i < len(opportunities)-1; i++ {
-		for j := i + 1; j < len(opportunities); j++ {
-			if opportunities[i].Spread < opportunities[j].Spread {
-				opportunities[i], opportunities[j] = opportunities[j], opportunities[i]
+	// Sort by spread descending, ties by exchange names
+	sortOpportunitiesBy(opportunities, spreadValue)
+
+	return opportunities
+}
+
+// clone returns a deep copy of d
+func (d *FundingRatesData) clone() *FundingRatesData {
+	cp := &FundingRatesData{
+		Symbols: append([]string(nil), d.Symbols...),
+		Exchanges: ExchangesData{
+			ExchangeNames: append([]ExchangeInfo(nil), d.Exchanges.ExchangeNames...),
+			Exchanges:     append([]string(nil), d.Exchanges.Exchanges...),
+		},
+		DefaultOIRank: d.DefaultOIRank,
+		Timestamp:     d.Timestamp,
+		warnings:      append([]DecodeWarning(nil), d.warnings...),
+	}
+	if d.FundingRates != nil {
+		cp.FundingRates = make(map[string]map[string]int, len(d.FundingRates))
+		for exchange, rates := range d.FundingRates {
+			cp.FundingRates[exchange] = make(map[string]int, len(rates))
+			for symbol, rate := range rates {
+				cp.FundingRates[exchange][symbol] = rate
 			}
 		}
 	}
+	if d.OIRankings != nil {
+		cp.OIRankings = make(map[string]string, len(d.OIRankings))
+		for symbol, rank := range d.OIRankings {
+			cp.OIRankings[symbol] = rank
+		}
+	}
+	return cp
+}
 
-	return opportunities, nil
+// symbolRates collects the raw rate of every exchange reporting symbol
+func (d *FundingRatesData) symbolRates(symbol string) map[string]int {
+	rates := make(map[string]int)
+	for exchange, symbols := range d.FundingRates {
+		if rate, ok := symbols[symbol]; ok {
+			rates[exchange] = rate
+		}
+	}
+	return rates
+}
+
+// Coverage returns how many exchanges report a rate for symbol
+func (d *FundingRatesData) Coverage(symbol string) int {
+	n := 0
+	for _, symbols := range d.FundingRates {
+		if _, ok := symbols[symbol]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// CoverageMatrix returns which exchange reports which symbol as a grid:
+// present[i][j] is true if exchanges[i] has a rate for symbols[j]. Both axes
+// are sorted; symbols includes the listed symbols and any others with rates.
+// The rows share one backing slice.
+func (d *FundingRatesData) CoverageMatrix() (exchanges, symbols []string, present [][]bool) {
+	seen := make(map[string]bool, len(d.Symbols))
+	for _, symbol := range d.Symbols {
+		seen[symbol] = true
+	}
+	for exchange, rates := range d.FundingRates {
+		exchanges = append(exchanges, exchange)
+		for symbol := range rates {
+			seen[symbol] = true
+		}
+	}
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(exchanges)
+	sort.Strings(symbols)
+
+	cells := make([]bool, len(exchanges)*len(symbols))
+	present = make([][]bool, len(exchanges))
+	for i, exchange := range exchanges {
+		present[i] = cells[i*len(symbols) : (i+1)*len(symbols)]
+		for j, symbol := range symbols {
+			_, present[i][j] = d.FundingRates[exchange][symbol]
+		}
+	}
+	return exchanges, symbols, present
+}
+
+// displayName returns the display name of an exchange, or its key if unknown
+func (d *FundingRatesData) displayName(exchange string) string {
+	for _, info := range d.Exchanges.ExchangeNames {
+		if info.Name == exchange && info.Display != "" {
+			return info.Display
+		}
+	}
+	return exchange
+}
+
+// DisplayNames resolves the display names of several exchanges at once,
+// building the lookup from ExchangeNames a single time. Exchanges without a
+// display name map to themselves.
+func (d *FundingRatesData) DisplayNames(exchanges []string) map[string]string {
+	lookup := make(map[string]string, len(d.Exchanges.ExchangeNames))
+	for _, info := range d.Exchanges.ExchangeNames {
+		if _, ok := lookup[info.Name]; !ok && info.Display != "" {
+			lookup[info.Name] = info.Display
+		}
+	}
+
+	names := make(map[string]string, len(exchanges))
+	for _, exchange := range exchanges {
+		if display, ok := lookup[exchange]; ok {
+			names[exchange] = display
+		} else {
+			names[exchange] = exchange
+		}
+	}
+	return names
 }
 
 func abs(x float64) float64 {
@@ -194,4 +786,3 @@ func abs(x float64) float64 {
 	}
 	return x
 }
-
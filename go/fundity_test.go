@@ -0,0 +1,267 @@
+package onlyfunding
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newStubClient returns a client whose requests are answered with data
+// without touching the network
+func newStubClient(t testing.TB, data *FundingRatesData, opts ...Option) *Client {
+	t.Helper()
+	body, err := json.Marshal(data)
+	if err != nil {
+		t.Fatalf("marshal stub data: %v", err)
+	}
+	stub := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusOK,
+			Status:     "200 OK",
+			Header:     http.Header{"Content-Type": []string{contentTypeJSON}},
+			Body:       io.NopCloser(bytes.NewReader(body)),
+			Request:    req,
+		}, nil
+	})
+	return NewClientWithOptions(DefaultBaseURL, time.Second, append([]Option{WithRoundTripper(stub)}, opts...)...)
+}
+
+func TestRequestContextUsesTighterDeadline(t *testing.T) {
+	const timeout = time.Minute
+	c := NewClientWithOptions(DefaultBaseURL, timeout)
+
+	t.Run("caller deadline earlier", func(t *testing.T) {
+		parent, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		want, _ := parent.Deadline()
+
+		ctx, cancelReq := c.requestContext(parent)
+		defer cancelReq()
+		got, ok := ctx.Deadline()
+		if !ok || !got.Equal(want) {
+			t.Errorf("deadline = %v, %v; want the caller's %v", got, ok, want)
+		}
+	})
+
+	t.Run("caller deadline later", func(t *testing.T) {
+		parent, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+
+		before := time.Now()
+		ctx, cancelReq := c.requestContext(parent)
+		defer cancelReq()
+		got, ok := ctx.Deadline()
+		if !ok {
+			t.Fatal("no deadline")
+		}
+		if got.Before(before.Add(timeout)) || got.After(time.Now().Add(timeout)) {
+			t.Errorf("deadline = %v, want now plus the client timeout %v", got, timeout)
+		}
+	})
+
+	t.Run("no caller deadline", func(t *testing.T) {
+		ctx, cancelReq := c.requestContext(context.Background())
+		defer cancelReq()
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("no deadline, want the client timeout")
+		}
+	})
+
+	t.Run("no client timeout", func(t *testing.T) {
+		c := NewClientWithOptions(DefaultBaseURL, 0)
+		ctx, cancelReq := c.requestContext(context.Background())
+		defer cancelReq()
+		if d, ok := ctx.Deadline(); ok {
+			t.Errorf("deadline = %v, want none", d)
+		}
+	})
+}
+
+func TestOpportunityBPFieldsMatchDecodedRates(t *testing.T) {
+	data := ExampleFundingRatesData()
+	check := func(t *testing.T, opps []ArbitrageOpportunity) {
+		t.Helper()
+		if len(opps) == 0 {
+			t.Fatal("no opportunities")
+		}
+		for _, o := range opps {
+			rate1 := data.FundingRates[o.Exchange1][o.Symbol]
+			rate2 := data.FundingRates[o.Exchange2][o.Symbol]
+			spread := rate1 - rate2
+			if spread < 0 {
+				spread = -spread
+			}
+			if o.Rate1BP != rate1 || o.Rate2BP != rate2 || o.SpreadBP != spread {
+				t.Errorf("%s %s/%s: BP fields %d, %d, %d; want %d, %d, %d",
+					o.Symbol, o.Exchange1, o.Exchange2, o.Rate1BP, o.Rate2BP, o.SpreadBP, rate1, rate2, spread)
+			}
+		}
+	}
+
+	t.Run("default", func(t *testing.T) {
+		c := newStubClient(t, data)
+		opps, err := c.FindAllArbitrageOpportunities(0)
+		if err != nil {
+			t.Fatalf("FindAllArbitrageOpportunities error: %v", err)
+		}
+		check(t, opps)
+		for _, o := range opps {
+			if o.Spread != float64(o.SpreadBP)/10000.0 {
+				t.Errorf("%s: Spread %v doesn't match SpreadBP %d", o.ID(), o.Spread, o.SpreadBP)
+			}
+		}
+	})
+
+	t.Run("hourly normalization", func(t *testing.T) {
+		c := newStubClient(t, data,
+			WithFundingIntervals(map[string]time.Duration{"hyperliquid_1_perp": time.Hour}),
+			WithHourlyNormalization())
+		opps, err := c.FindAllArbitrageOpportunities(0)
+		if err != nil {
+			t.Fatalf("FindAllArbitrageOpportunities error: %v", err)
+		}
+		check(t, opps)
+	})
+}
+
+// countRequests returns middleware counting the requests sent through it
+func countRequests(n *int32) Middleware {
+	return func(next http.RoundTripper) http.RoundTripper {
+		return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			atomic.AddInt32(n, 1)
+			return next.RoundTrip(req)
+		})
+	}
+}
+
+func TestGetRatesDuplicateAndMissingPairs(t *testing.T) {
+	var requests int32
+	c := newStubClient(t, ExampleFundingRatesData(), WithMiddleware(countRequests(&requests)))
+
+	btcBinance := RatePair{Exchange: "binance_1_perp", Symbol: "BTC"}
+	btcOKX := RatePair{Exchange: "okx_1_perp", Symbol: "BTC"}
+	ethBinance := RatePair{Exchange: "binance_1_perp", Symbol: "ETH"}
+	dogeBybit := RatePair{Exchange: "bybit_1_perp", Symbol: "DOGE"}
+	unknown := RatePair{Exchange: "nowhere", Symbol: "BTC"}
+
+	rates, err := c.GetRates([]RatePair{btcBinance, btcOKX, btcBinance, ethBinance, dogeBybit, unknown, dogeBybit})
+	if got := atomic.LoadInt32(&requests); got != 1 {
+		t.Errorf("GetRates sent %d requests, want 1", got)
+	}
+
+	want := map[RatePair]float64{btcBinance: 0.0008, btcOKX: 0.0005, ethBinance: -0.0015}
+	if len(rates) != len(want) {
+		t.Errorf("rates = %v, want %v", rates, want)
+	}
+	for pair, rate := range want {
+		if got, ok := rates[pair]; !ok || got != rate {
+			t.Errorf("rates[%v] = %v, %v; want %v", pair, got, ok, rate)
+		}
+	}
+
+	if !errors.Is(err, ErrRateNotFound) {
+		t.Fatalf("error = %v, want ErrRateNotFound", err)
+	}
+	const wantErr = "rate not found for DOGE on bybit_1_perp, BTC on nowhere"
+	if err.Error() != wantErr {
+		t.Errorf("error = %q, want %q", err, wantErr)
+	}
+
+	rates, err = c.GetRates([]RatePair{btcOKX, btcOKX})
+	if err != nil || len(rates) != 1 || rates[btcOKX] != 0.0005 {
+		t.Errorf("GetRates(duplicates) = %v, %v", rates, err)
+	}
+}
+
+func TestFindArbitrageOpportunitiesBPThresholdsRawSpread(t *testing.T) {
+	// with hyperliquid quoted per hour, its BTC rate of 40 is 40bp per hour
+	// against 1bp per hour on binance, but the raw spread stays 32bp
+	c := newStubClient(t, ExampleFundingRatesData(),
+		WithFundingIntervals(map[string]time.Duration{"hyperliquid_1_perp": time.Hour}),
+		WithHourlyNormalization())
+
+	for _, minSpreadBP := range []int{32, 33} {
+		opps, err := c.FindArbitrageOpportunitiesBP("BTC", minSpreadBP)
+		if err != nil {
+			t.Fatalf("FindArbitrageOpportunitiesBP error: %v", err)
+		}
+		found := false
+		for _, o := range opps {
+			if o.SpreadBP < minSpreadBP {
+				t.Errorf("minSpreadBP %d kept %s/%s with SpreadBP %d", minSpreadBP, o.Exchange1, o.Exchange2, o.SpreadBP)
+			}
+			if o.Exchange1 == "binance_1_perp" && o.Exchange2 == "hyperliquid_1_perp" {
+				found = true
+			}
+		}
+		if want := minSpreadBP <= 32; found != want {
+			t.Errorf("minSpreadBP %d: binance/hyperliquid kept = %v, want %v", minSpreadBP, found, want)
+		}
+	}
+}
+
+func TestContextMethodsHonorCancellation(t *testing.T) {
+	var hits int32
+	arrived := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		arrived <- struct{}{}
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+	c := NewClientWithOptions(srv.URL, 5*time.Second)
+
+	methods := []struct {
+		name string
+		call func(ctx context.Context) error
+	}{
+		{"GetFundingRatesContext", func(ctx context.Context) error {
+			_, err := c.GetFundingRatesContext(ctx)
+			return err
+		}},
+		{"GetRateContext", func(ctx context.Context) error {
+			_, err := c.GetRateContext(ctx, "binance_1_perp", "BTC")
+			return err
+		}},
+		{"GetSymbolsContext", func(ctx context.Context) error {
+			_, err := c.GetSymbolsContext(ctx)
+			return err
+		}},
+		{"FindAllArbitrageOpportunitiesContext", func(ctx context.Context) error {
+			_, err := c.FindAllArbitrageOpportunitiesContext(ctx, 0)
+			return err
+		}},
+	}
+	for _, m := range methods {
+		t.Run(m.name+" cancelled mid-request", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				<-arrived
+				cancel()
+			}()
+			if err := m.call(ctx); !errors.Is(err, context.Canceled) {
+				t.Errorf("error = %v, want context.Canceled", err)
+			}
+		})
+
+		t.Run(m.name+" expired deadline", func(t *testing.T) {
+			ctx, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+			defer cancel()
+			before := atomic.LoadInt32(&hits)
+			if err := m.call(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("error = %v, want context.DeadlineExceeded", err)
+			}
+			if got := atomic.LoadInt32(&hits); got != before {
+				t.Errorf("request sent under an expired deadline")
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package onlyfunding
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Ping checks that the API is reachable and answering successfully
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.PingWithLatency(ctx)
+	return err
+}
+
+// PingWithLatency is Ping that also reports how long the API took to respond,
+// measured from sending the request to receiving the response headers. The
+// latency is returned for error statuses too, so slow failures are visible;
+// it is zero only if no response arrived.
+func (c *Client) PingWithLatency(ctx context.Context) (time.Duration, error) {
+	ctx, cancel := c.requestContext(ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/funding", c.endpoint()), nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reach API: %w", err)
+	}
+	latency := time.Since(start)
+	defer resp.Body.Close()
+	c.recordResponse(resp)
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxSnippetSize))
+		return latency, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return latency, nil
+}
+
+// WithSkipReadinessProbe makes NewClientWithReadiness return the client
+// without probing, for tests and offline use
+func WithSkipReadinessProbe() Option {
+	return func(c *Client) {
+		c.skipReadiness = true
+	}
+}
+
+// NewClientWithReadiness is NewClientWithOptions followed by a readiness
+// probe: one fetch from baseURL under ctx whose result must pass Validate. It
+// fails fast on a wrong base URL or an endpoint serving unusable data instead
+// of leaving that to the first real request. Pass DefaultBaseURL and
+// DefaultTimeout for the public API. Use WithSkipReadinessProbe to disable
+// the probe.
+func NewClientWithReadiness(ctx context.Context, baseURL string, timeout time.Duration, opts ...Option) (*Client, error) {
+	c := NewClientWithOptions(baseURL, timeout, opts...)
+	if c.skipReadiness {
+		return c, nil
+	}
+
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err == nil {
+		err = data.Validate()
+	}
+	if err != nil {
+		c.Close()
+		return nil, fmt.Errorf("readiness probe failed: %w", err)
+	}
+	return c, nil
+}
@@ -0,0 +1,39 @@
+package onlyfunding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestNewClientWithReadinessProbesBaseURL(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/funding" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.Write(ExampleFundingRatesJSON())
+	}))
+	defer srv.Close()
+
+	c, err := NewClientWithReadiness(context.Background(), srv.URL+"/v1", time.Second)
+	if err != nil {
+		t.Fatalf("probe of a healthy endpoint failed: %v", err)
+	}
+	c.Close()
+
+	_, err = NewClientWithReadiness(context.Background(), srv.URL+"/wrong", time.Second)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("probe of a wrong base URL returned %v, want a 404 APIError", err)
+	}
+
+	c, err = NewClientWithReadiness(context.Background(), srv.URL+"/wrong", time.Second, WithSkipReadinessProbe())
+	if err != nil || c == nil {
+		t.Errorf("skipped probe returned %v, %v", c, err)
+	}
+}
@@ -0,0 +1,413 @@
+package onlyfunding
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultHistoryCapacity is the buffer size used when NewHistory is given a
+// non-positive capacity: one day of snapshots at the API's 60s refresh rate
+const DefaultHistoryCapacity = 1440
+
+// MinZScoreSamples is the number of snapshots SpreadZScore needs within its
+// window before it reports a value
+const MinZScoreSamples = 10
+
+// MinCorrelationSamples is the number of snapshots with both exchanges
+// reporting that ExchangeCorrelation needs before it correlates a pair
+const MinCorrelationSamples = 10
+
+// MinPersistenceSamples is the number of closed lifecycles of an opportunity
+// OpportunityPersistence needs before it reports a value
+const MinPersistenceSamples = 3
+
+// maxLifecycles bounds how many closed lifecycle durations are kept per
+// opportunity
+const maxLifecycles = 100
+
+// Snapshot is a FundingRatesData recorded at a point in time
+type Snapshot struct {
+	Time time.Time
+	Data *FundingRatesData
+}
+
+// History keeps a bounded buffer of recent snapshots, oldest first, and the
+// running signals derived from them. It is safe for concurrent use: writers
+// hold an exclusive lock and each read method sees a consistent buffer for its
+// whole computation.
+type History struct {
+	mu sync.RWMutex
+
+	capacity  int
+	snapshots []Snapshot
+	emas      map[emaKey]float64
+
+	// opportunity lifecycles, keyed by ArbitrageOpportunity.ID
+	persistenceMinSpread float64
+	openSince            map[string]time.Time
+	lifecycles           map[string][]time.Duration
+}
+
+type emaKey struct {
+	symbol   string
+	exchange string
+	alpha    float64
+}
+
+// NewHistory creates a history holding at most capacity snapshots
+func NewHistory(capacity int) *History {
+	if capacity <= 0 {
+		capacity = DefaultHistoryCapacity
+	}
+	return &History{
+		capacity:   capacity,
+		emas:       make(map[emaKey]float64),
+		openSince:  make(map[string]time.Time),
+		lifecycles: make(map[string][]time.Duration),
+	}
+}
+
+// Add records a snapshot taken now
+func (h *History) Add(snapshot *FundingRatesData) {
+	h.AddAt(snapshot, time.Now())
+}
+
+// AddAt records a snapshot taken at the given time, evicting the oldest one
+// once the buffer is full
+func (h *History) AddAt(snapshot *FundingRatesData, at time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if snapshot == nil {
+		return
+	}
+
+	if len(h.snapshots) == h.capacity {
+		h.snapshots = append(h.snapshots[:0], h.snapshots[1:]...)
+	}
+	h.snapshots = append(h.snapshots, Snapshot{Time: at, Data: snapshot})
+
+	for key, ema := range h.emas {
+		if rate, ok := snapshot.FundingRates[key.exchange][key.symbol]; ok {
+			h.emas[key] = key.alpha*(float64(rate)/10000.0) + (1-key.alpha)*ema
+		}
+	}
+
+	h.trackLifecycles(snapshot, at)
+}
+
+// Len returns the number of buffered snapshots
+func (h *History) Len() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return len(h.snapshots)
+}
+
+// EMA returns the exponential moving average of an exchange's rate for a
+// symbol. alpha in (0, 1] is the weight given to each new observation: values
+// near 1 track the latest rate closely, small values smooth heavily. The
+// average is seeded from the buffered snapshots on first use and then updated
+// by every Add, so it keeps running after old snapshots are evicted. Snapshots
+// where the exchange doesn't report the symbol leave it unchanged. It returns
+// false if alpha is out of range or the rate was never observed.
+func (h *History) EMA(symbol, exchange string, alpha float64) (float64, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if alpha <= 0 || alpha > 1 {
+		return 0, false
+	}
+
+	key := emaKey{symbol: symbol, exchange: exchange, alpha: alpha}
+	if ema, ok := h.emas[key]; ok {
+		return ema, true
+	}
+
+	var ema float64
+	seeded := false
+	for _, s := range h.snapshots {
+		rate, ok := s.Data.FundingRates[exchange][symbol]
+		if !ok {
+			continue
+		}
+		if !seeded {
+			ema = float64(rate) / 10000.0
+			seeded = true
+			continue
+		}
+		ema = alpha*(float64(rate)/10000.0) + (1-alpha)*ema
+	}
+	if !seeded {
+		return 0, false
+	}
+
+	h.emas[key] = ema
+	return ema, true
+}
+
+// SpreadZScore returns how many standard deviations the latest spread between
+// two exchanges' rates for a symbol (rate A minus rate B) sits from its mean
+// over the snapshots taken within window of the latest one. Snapshots missing
+// either leg are skipped. It returns false if the latest snapshot lacks a
+// leg, fewer than MinZScoreSamples snapshots qualify, or the spread never
+// moved.
+func (h *History) SpreadZScore(symbol, exchangeA, exchangeB string, window time.Duration) (float64, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.snapshots) == 0 {
+		return 0, false
+	}
+
+	latest := h.snapshots[len(h.snapshots)-1]
+	current, ok := pairSpread(latest.Data, symbol, exchangeA, exchangeB)
+	if !ok {
+		return 0, false
+	}
+
+	start := latest.Time.Add(-window)
+	var samples []float64
+	for _, s := range h.snapshots {
+		if s.Time.Before(start) {
+			continue
+		}
+		if spread, ok := pairSpread(s.Data, symbol, exchangeA, exchangeB); ok {
+			samples = append(samples, spread)
+		}
+	}
+	if len(samples) < MinZScoreSamples {
+		return 0, false
+	}
+
+	mean, std := meanStdDev(samples)
+	if std == 0 {
+		return 0, false
+	}
+	return (current - mean) / std, true
+}
+
+// pairSpread returns exchangeA's rate minus exchangeB's for symbol
+func pairSpread(d *FundingRatesData, symbol, exchangeA, exchangeB string) (float64, bool) {
+	rateA, okA := d.FundingRates[exchangeA][symbol]
+	rateB, okB := d.FundingRates[exchangeB][symbol]
+	if !okA || !okB {
+		return 0, false
+	}
+	return float64(rateA-rateB) / 10000.0, true
+}
+
+// meanStdDev returns the mean and population standard deviation of xs
+func meanStdDev(xs []float64) (float64, float64) {
+	var sum float64
+	for _, x := range xs {
+		sum += x
+	}
+	mean := sum / float64(len(xs))
+
+	var sq float64
+	for _, x := range xs {
+		sq += (x - mean) * (x - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(xs)))
+}
+
+// FreshnessLag returns, for each buffered snapshot oldest first, how far its
+// server timestamp trailed the time it was recorded. A growing lag means the
+// feed is falling behind rather than having a one-off blip. Snapshots with an
+// unparseable timestamp are skipped. The values are only meaningful if the
+// local clock is synchronized with the server's.
+func (h *History) FreshnessLag() []time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	lags := make([]time.Duration, 0, len(h.snapshots))
+	for _, s := range h.snapshots {
+		serverTime, err := parseTimestamp(s.Data.Timestamp)
+		if err != nil {
+			continue
+		}
+		lags = append(lags, s.Time.Sub(serverTime))
+	}
+	return lags
+}
+
+// ExchangeCorrelation returns the Pearson correlation of every exchange
+// pair's rate series for symbol across the buffered snapshots, keyed both
+// ways round ([a][b] and [b][a]). Only snapshots where both exchanges report
+// the symbol count, and pairs with fewer than MinCorrelationSamples of them
+// are omitted. If either series is constant the correlation is undefined and
+// reported as NaN.
+func (h *History) ExchangeCorrelation(symbol string) map[string]map[string]float64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	series := make(map[string][]float64)
+	present := make(map[string][]bool)
+	for i, s := range h.snapshots {
+		for exchange, rates := range s.Data.FundingRates {
+			rate, ok := rates[symbol]
+			if !ok {
+				continue
+			}
+			if series[exchange] == nil {
+				series[exchange] = make([]float64, len(h.snapshots))
+				present[exchange] = make([]bool, len(h.snapshots))
+			}
+			series[exchange][i] = float64(rate) / 10000.0
+			present[exchange][i] = true
+		}
+	}
+
+	exchanges := make([]string, 0, len(series))
+	for exchange := range series {
+		exchanges = append(exchanges, exchange)
+	}
+
+	correlations := make(map[string]map[string]float64)
+	for i, a := range exchanges {
+		for _, b := range exchanges[i+1:] {
+			var xs, ys []float64
+			for k := range h.snapshots {
+				if present[a][k] && present[b][k] {
+					xs = append(xs, series[a][k])
+					ys = append(ys, series[b][k])
+				}
+			}
+			if len(xs) < MinCorrelationSamples {
+				continue
+			}
+
+			r := pearson(xs, ys)
+			if correlations[a] == nil {
+				correlations[a] = make(map[string]float64)
+			}
+			if correlations[b] == nil {
+				correlations[b] = make(map[string]float64)
+			}
+			correlations[a][b] = r
+			correlations[b][a] = r
+		}
+	}
+	return correlations
+}
+
+// pearson returns the correlation coefficient of xs and ys, or NaN if either
+// is constant
+func pearson(xs, ys []float64) float64 {
+	meanX, stdX := meanStdDev(xs)
+	meanY, stdY := meanStdDev(ys)
+	if stdX == 0 || stdY == 0 {
+		return math.NaN()
+	}
+
+	var cov float64
+	for i := range xs {
+		cov += (xs[i] - meanX) * (ys[i] - meanY)
+	}
+	cov /= float64(len(xs))
+	return cov / (stdX * stdY)
+}
+
+// CompareWindow diffs the oldest snapshot taken within window of the latest
+// one against the latest, giving the movers over that period. It returns nil
+// if the window holds fewer than two snapshots, or if it reaches back past the
+// oldest snapshot still held, since the changes since its start are unknown.
+func (h *History) CompareWindow(window time.Duration) []RateChange {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if len(h.snapshots) < 2 {
+		return nil
+	}
+
+	latest := h.snapshots[len(h.snapshots)-1]
+	start := latest.Time.Add(-window)
+	if h.snapshots[0].Time.After(start) {
+		return nil
+	}
+	for _, s := range h.snapshots[:len(h.snapshots)-1] {
+		if !s.Time.Before(start) {
+			return Diff(s.Data, latest.Data)
+		}
+	}
+	return nil
+}
+
+// SetPersistenceMinSpread sets the spread an opportunity must reach to count
+// as open for OpportunityPersistence. Defaults to 0, so any non-zero spread
+// counts. It applies from the next Add.
+func (h *History) SetPersistenceMinSpread(minSpread float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.persistenceMinSpread = minSpread
+}
+
+// OpportunityPersistence estimates how long the opportunity with the given
+// ArbitrageOpportunity.ID typically stays open: the median duration of its
+// past lifecycles, each running from the first snapshot it appeared in to the
+// first one it was gone from. Lifecycles are tracked by every Add, so the
+// estimate survives eviction, but it is only as good as the history behind
+// it: it returns false until MinPersistenceSamples lifecycles have closed.
+// Durations are resolved to the interval between snapshots.
+func (h *History) OpportunityPersistence(id string) (time.Duration, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	durations := h.lifecycles[id]
+	if len(durations) < MinPersistenceSamples {
+		return 0, false
+	}
+
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2, true
+	}
+	return sorted[mid], true
+}
+
+// trackLifecycles opens lifecycles for opportunities appearing in snapshot
+// and closes those that disappeared
+func (h *History) trackLifecycles(snapshot *FundingRatesData, at time.Time) {
+	open := make(map[string]bool)
+	for _, symbol := range snapshot.Symbols {
+		for _, opp := range snapshot.arbitrageOpportunities(symbol, h.persistenceMinSpread, &scanConfig{}) {
+			id := opp.ID()
+			open[id] = true
+			if _, ok := h.openSince[id]; !ok {
+				h.openSince[id] = at
+			}
+		}
+	}
+
+	for id, since := range h.openSince {
+		if open[id] {
+			continue
+		}
+		durations := append(h.lifecycles[id], at.Sub(since))
+		if len(durations) > maxLifecycles {
+			durations = durations[len(durations)-maxLifecycles:]
+		}
+		h.lifecycles[id] = durations
+		delete(h.openSince, id)
+	}
+}
+
+// TimePoint is a value observed at a point in time
+type TimePoint struct {
+	Time   time.Time
+	Spread float64
+}
+
+// SpreadSeries returns the spread between two exchanges' rates for symbol
+// (rate A minus rate B) for each buffered snapshot, oldest first. Snapshots
+// missing either leg are skipped rather than reported as gaps, so
+// consecutive points may be further apart than the polling interval.
+func (h *History) SpreadSeries(symbol, exchangeA, exchangeB string) []TimePoint {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var series []TimePoint
+	for _, s := range h.snapshots {
+		if spread, ok := pairSpread(s.Data, symbol, exchangeA, exchangeB); ok {
+			series = append(series, TimePoint{Time: s.Time, Spread: spread})
+		}
+	}
+	return series
+}
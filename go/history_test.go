@@ -0,0 +1,78 @@
+package onlyfunding
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestHistoryConcurrentAccess is meant to be run with -race: one writer adds
+// snapshots while several readers query every derived signal
+func TestHistoryConcurrentAccess(t *testing.T) {
+	const (
+		capacity  = 50
+		snapshots = 200
+		readers   = 8
+	)
+	h := NewHistory(capacity)
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(done)
+		for i := 0; i < snapshots; i++ {
+			data := ExampleFundingRatesData()
+			data.FundingRates["hyperliquid_1_perp"]["BTC"] = 40 + i%7
+			h.AddAt(data, start.Add(time.Duration(i)*time.Minute))
+		}
+	}()
+
+	for r := 0; r < readers; r++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				h.Len()
+				h.EMA("BTC", "hyperliquid_1_perp", 0.2)
+				h.SpreadZScore("BTC", "hyperliquid_1_perp", "binance_1_perp", time.Hour)
+				h.CompareWindow(10 * time.Minute)
+				h.ExchangeCorrelation("BTC")
+				h.FreshnessLag()
+				h.SpreadSeries("BTC", "hyperliquid_1_perp", "binance_1_perp")
+				h.OpportunityPersistence("missing")
+			}
+		}()
+	}
+
+	wg.Wait()
+	if got := h.Len(); got != capacity {
+		t.Errorf("Len() = %d, want %d", got, capacity)
+	}
+}
+
+func TestCompareWindowNeedsCoverage(t *testing.T) {
+	h := NewHistory(3)
+	start := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		data := ExampleFundingRatesData()
+		data.FundingRates["binance_1_perp"]["BTC"] = 8 + i
+		h.AddAt(data, start.Add(time.Duration(i)*time.Minute))
+	}
+
+	// the buffer holds minutes 2 to 4
+	if changes := h.CompareWindow(2 * time.Minute); len(changes) != 1 || changes[0].Delta != 0.0002 {
+		t.Errorf("CompareWindow(2m) = %+v, want BTC on binance up 2bp", changes)
+	}
+	if changes := h.CompareWindow(3 * time.Minute); changes != nil {
+		t.Errorf("CompareWindow(3m) = %+v, want nil for a window older than the buffer", changes)
+	}
+}
@@ -0,0 +1,107 @@
+package onlyfunding
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Symbol is a market symbol such as "BTC". Using it, with Exchange, in the
+// typed methods like RateOf is preferred over plain strings, since the
+// compiler then catches a symbol passed where an exchange was expected.
+type Symbol string
+
+// Exchange is an exchange key as it appears in FundingRates, such as
+// "binance_1_perp"
+type Exchange string
+
+// NewSymbol validates s as a symbol: it must be non-empty and contain no
+// whitespace
+func NewSymbol(s string) (Symbol, error) {
+	if err := validateIdentifier("symbol", s); err != nil {
+		return "", err
+	}
+	return Symbol(s), nil
+}
+
+// NewExchange validates s as an exchange key: it must be non-empty and
+// contain no whitespace
+func NewExchange(s string) (Exchange, error) {
+	if err := validateIdentifier("exchange", s); err != nil {
+		return "", err
+	}
+	return Exchange(s), nil
+}
+
+// validateIdentifier checks the rules shared by symbols and exchange keys
+func validateIdentifier(kind, s string) error {
+	if s == "" {
+		return fmt.Errorf("empty %s", kind)
+	}
+	if strings.IndexFunc(s, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("invalid %s %q: contains whitespace", kind, s)
+	}
+	return nil
+}
+
+func (s Symbol) String() string { return string(s) }
+
+func (e Exchange) String() string { return string(e) }
+
+// Canonical returns the venue name with any per-contract suffix removed, as
+// CanonicalExchange does
+func (e Exchange) Canonical() string {
+	return CanonicalExchange(string(e))
+}
+
+// KnownSymbols returns the symbols data lists, sorted
+func (d *FundingRatesData) KnownSymbols() []Symbol {
+	symbols := make([]Symbol, len(d.Symbols))
+	for i, symbol := range d.Symbols {
+		symbols[i] = Symbol(symbol)
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i] < symbols[j] })
+	return symbols
+}
+
+// KnownExchanges returns the exchanges data has rates for, sorted
+func (d *FundingRatesData) KnownExchanges() []Exchange {
+	exchanges := make([]Exchange, 0, len(d.FundingRates))
+	for exchange := range d.FundingRates {
+		exchanges = append(exchanges, Exchange(exchange))
+	}
+	sort.Slice(exchanges, func(i, j int) bool { return exchanges[i] < exchanges[j] })
+	return exchanges
+}
+
+// LookupSymbol returns s as a Symbol if data lists it. An unlisted symbol
+// yields an error matching ErrSymbolNotFound.
+func (d *FundingRatesData) LookupSymbol(s string) (Symbol, error) {
+	for _, symbol := range d.Symbols {
+		if symbol == s {
+			return Symbol(s), nil
+		}
+	}
+	return "", fmt.Errorf("unknown symbol %s: %w", s, ErrSymbolNotFound)
+}
+
+// LookupExchange returns s as an Exchange if data has rates for it. An
+// exchange without rates yields an error matching ErrExchangeNotFound.
+func (d *FundingRatesData) LookupExchange(s string) (Exchange, error) {
+	if _, ok := d.FundingRates[s]; !ok {
+		return "", fmt.Errorf("unknown exchange %s: %w", s, ErrExchangeNotFound)
+	}
+	return Exchange(s), nil
+}
+
+// RateOf is GetRate with typed arguments
+func (c *Client) RateOf(exchange Exchange, symbol Symbol) (float64, error) {
+	return c.RateOfContext(context.Background(), exchange, symbol)
+}
+
+// RateOfContext is RateOf under ctx
+func (c *Client) RateOfContext(ctx context.Context, exchange Exchange, symbol Symbol) (float64, error) {
+	return c.GetRateContext(ctx, string(exchange), string(symbol))
+}
@@ -0,0 +1,56 @@
+package onlyfunding
+
+import (
+	"context"
+	"time"
+)
+
+// FundingClient is the set of Client methods that talk to the API. Depend on
+// it instead of *Client to substitute a fake in tests. Methods that only
+// compute from configuration, such as Interval or AnnualizedSpread, are left
+// out.
+type FundingClient interface {
+	GetFundingRates() (*FundingRatesData, error)
+	GetFundingRatesContext(ctx context.Context) (*FundingRatesData, error)
+	GetFundingRatesFiltered(ctx context.Context, symbols []string) (*FundingRatesData, error)
+	GetExchangeRates(ctx context.Context, exchange string) (*FundingRatesData, error)
+	Refresh() (*FundingRatesData, error)
+	RefreshContext(ctx context.Context) (*FundingRatesData, error)
+	GetRate(exchange, symbol string) (float64, error)
+	GetRateContext(ctx context.Context, exchange, symbol string) (float64, error)
+	GetRates(pairs []RatePair) (map[RatePair]float64, error)
+	GetRatesContext(ctx context.Context, pairs []RatePair) (map[RatePair]float64, error)
+	GetRateWithInterval(exchange, symbol string) (float64, time.Duration, error)
+	GetRateWithIntervalContext(ctx context.Context, exchange, symbol string) (float64, time.Duration, error)
+	RateOf(exchange Exchange, symbol Symbol) (float64, error)
+	RateOfContext(ctx context.Context, exchange Exchange, symbol Symbol) (float64, error)
+	GetExchanges() ([]ExchangeInfo, error)
+	GetExchangesContext(ctx context.Context) ([]ExchangeInfo, error)
+	GetSymbols() ([]string, error)
+	GetSymbolsContext(ctx context.Context) ([]string, error)
+	GetRatesForSymbol(symbol string) (map[string]float64, error)
+	GetRatesForSymbolContext(ctx context.Context, symbol string) (map[string]float64, error)
+	RankExchangesForSymbol(symbol string) ([]SymbolRate, error)
+	RankExchangesForSymbolContext(ctx context.Context, symbol string) ([]SymbolRate, error)
+
+	FindArbitrageOpportunities(symbol string, minSpread float64) ([]ArbitrageOpportunity, error)
+	FindArbitrageOpportunitiesContext(ctx context.Context, symbol string, minSpread float64) ([]ArbitrageOpportunity, error)
+	FindArbitrageOpportunitiesBP(symbol string, minSpreadBP int) ([]ArbitrageOpportunity, error)
+	FindArbitrageOpportunitiesBPContext(ctx context.Context, symbol string, minSpreadBP int) ([]ArbitrageOpportunity, error)
+	FindArbitrageGroupedBySymbol(minSpread float64) (map[string][]ArbitrageOpportunity, error)
+	FindArbitrageGroupedBySymbolContext(ctx context.Context, minSpread float64) (map[string][]ArbitrageOpportunity, error)
+	FindAllArbitrageOpportunities(minSpread float64) ([]ArbitrageOpportunity, error)
+	FindAllArbitrageOpportunitiesContext(ctx context.Context, minSpread float64) ([]ArbitrageOpportunity, error)
+	FindTopArbitrageOpportunities(minSpread float64, n int) ([]ArbitrageOpportunity, error)
+	FindTopArbitrageOpportunitiesContext(ctx context.Context, minSpread float64, n int) ([]ArbitrageOpportunity, error)
+	BestOpportunityPerPair(minSpread float64) (map[string]ArbitrageOpportunity, error)
+	BestOpportunityPerPairContext(ctx context.Context, minSpread float64) (map[string]ArbitrageOpportunity, error)
+	BestArbitrageAcrossMarket(minSpread float64) (ArbitrageOpportunity, bool, error)
+	BestArbitrageAcrossMarketContext(ctx context.Context, minSpread float64) (ArbitrageOpportunity, bool, error)
+
+	Ping(ctx context.Context) error
+	PingWithLatency(ctx context.Context) (time.Duration, error)
+	Close() error
+}
+
+var _ FundingClient = (*Client)(nil)
@@ -0,0 +1,163 @@
+package onlyfunding
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultFundingInterval is the interval a rate is assumed to cover when none
+// is configured for its exchange. The API already scales venues that fund
+// hourly up to 8h, so this is correct for API data as served.
+const DefaultFundingInterval = 8 * time.Hour
+
+// WithFundingIntervals sets the funding interval per exchange key, used when
+// annualizing or normalizing rates. Exchanges not in the map fall back to
+// DefaultFundingInterval.
+//
+// An interval here is the period a rate in the data is quoted over, not how
+// often the venue settles (see WithFundingSchedules for that). The API
+// already scales hourly venues such as Hyperliquid to 8h, so for API data
+// leave them at the default: configuring time.Hour would scale their rates
+// by 8 a second time. Set intervals only for data quoted over other periods,
+// such as snapshots merged from another source.
+func WithFundingIntervals(intervals map[string]time.Duration) Option {
+	return func(c *Client) {
+		c.intervals = make(map[string]time.Duration, len(intervals))
+		for exchange, interval := range intervals {
+			c.intervals[exchange] = interval
+		}
+	}
+}
+
+// Interval returns the funding interval of an exchange
+func (c *Client) Interval(exchange string) time.Duration {
+	if interval, ok := c.intervals[exchange]; ok && interval > 0 {
+		return interval
+	}
+	return DefaultFundingInterval
+}
+
+// GetRateWithInterval gets the funding rate for an exchange and symbol along
+// with the interval it is paid over
+func (c *Client) GetRateWithInterval(exchange, symbol string) (float64, time.Duration, error) {
+	return c.GetRateWithIntervalContext(context.Background(), exchange, symbol)
+}
+
+// GetRateWithIntervalContext is GetRateWithInterval under ctx
+func (c *Client) GetRateWithIntervalContext(ctx context.Context, exchange, symbol string) (float64, time.Duration, error) {
+	rate, err := c.GetRateContext(ctx, exchange, symbol)
+	if err != nil {
+		return 0, 0, err
+	}
+	return rate, c.Interval(exchange), nil
+}
+
+// WithHourlyNormalization makes client scans convert each exchange's rate to
+// a per-hour rate, dividing by its Interval, before comparing exchanges, so
+// venues funding every hour and every 8h are compared on the same basis.
+// Rate1, Rate2 and Spread of the opportunities found are then per hour, while
+// Rate1BP, Rate2BP and SpreadBP keep the raw values the API sent. Unlike
+// AnnualizedSpread this changes which pairs pass minSpread and how they
+// rank; FindArbitrageOpportunitiesBP still thresholds on the raw SpreadBP.
+// Off by default.
+//
+// Normalization only reorders anything when intervals differ. Since the API
+// serves every venue on an 8h basis, it matters only for data quoted over
+// other periods; see WithFundingIntervals before configuring hourly venues.
+func WithHourlyNormalization() Option {
+	return func(c *Client) {
+		c.scan.hourly = true
+	}
+}
+
+// quotedInterval returns the period the rates in c's opportunities are
+// quoted over on exchange
+func (c *Client) quotedInterval(exchange string) time.Duration {
+	if c.scan.hourly {
+		return time.Hour
+	}
+	return c.Interval(exchange)
+}
+
+// AnnualizedSpread returns the spread of o with each leg's rate annualized
+// over its own funding interval, so opportunities on venues with different
+// intervals compare like for like
+func (c *Client) AnnualizedSpread(o ArbitrageOpportunity) float64 {
+	return c.annualize(o.ShortExchange, o.ShortRate()) - c.annualize(o.LongExchange, o.LongRate())
+}
+
+// annualize scales a rate quoted per quotedInterval(exchange) to a year
+func (c *Client) annualize(exchange string, rate float64) float64 {
+	return rate * float64(hoursPerYear*time.Hour) / float64(c.quotedInterval(exchange))
+}
+
+// FundingSchedule describes when an exchange settles funding: every Interval,
+// aligned to the Unix epoch and shifted by Offset. With an 8h interval and no
+// offset, settlements fall at 00:00, 08:00 and 16:00 UTC.
+type FundingSchedule struct {
+	Interval time.Duration
+	Offset   time.Duration
+}
+
+// WithFundingSchedules sets the settlement schedule per exchange key.
+// Exchanges not in the map settle every DefaultFundingInterval from 00:00 UTC.
+func WithFundingSchedules(schedules map[string]FundingSchedule) Option {
+	return func(c *Client) {
+		c.schedules = make(map[string]FundingSchedule, len(schedules))
+		for exchange, schedule := range schedules {
+			c.schedules[exchange] = schedule
+		}
+	}
+}
+
+// Schedule returns the settlement schedule of an exchange
+func (c *Client) Schedule(exchange string) FundingSchedule {
+	if schedule, ok := c.schedules[exchange]; ok && schedule.Interval > 0 {
+		return schedule
+	}
+	return FundingSchedule{Interval: DefaultFundingInterval}
+}
+
+// NextFundingTime returns the first settlement on exchange strictly after now
+func (c *Client) NextFundingTime(exchange string, now time.Time) time.Time {
+	schedule := c.Schedule(exchange)
+	k := floorDiv(now.UnixNano()-int64(schedule.Offset), int64(schedule.Interval)) + 1
+	return time.Unix(0, k*int64(schedule.Interval)+int64(schedule.Offset)).In(now.Location())
+}
+
+// SettlementsBetween counts the settlements on exchange in (from, to]
+func (c *Client) SettlementsBetween(exchange string, from, to time.Time) int {
+	if !to.After(from) {
+		return 0
+	}
+	schedule := c.Schedule(exchange)
+	interval, offset := int64(schedule.Interval), int64(schedule.Offset)
+	return int(floorDiv(to.UnixNano()-offset, interval) - floorDiv(from.UnixNano()-offset, interval))
+}
+
+// EstimateCarry estimates the funding collected by holding both legs of o at
+// the given notional from opening at open for hold. Only settlements inside
+// the holding window count, so a position opened just after a settlement
+// earns nothing until the next one. Each leg's rate is scaled from its
+// quoted interval (see Interval) to its settlement interval (see Schedule).
+func (c *Client) EstimateCarry(o ArbitrageOpportunity, notional float64, open time.Time, hold time.Duration) float64 {
+	closeAt := open.Add(hold)
+	return notional * (c.settledFunding(o.ShortExchange, o.ShortRate(), open, closeAt) -
+		c.settledFunding(o.LongExchange, o.LongRate(), open, closeAt))
+}
+
+// settledFunding sums rate, quoted per quotedInterval(exchange), over the
+// settlements in (from, to]
+func (c *Client) settledFunding(exchange string, rate float64, from, to time.Time) float64 {
+	perSettlement := rate * float64(c.Schedule(exchange).Interval) / float64(c.quotedInterval(exchange))
+	return perSettlement * float64(c.SettlementsBetween(exchange, from, to))
+}
+
+// floorDiv divides rounding toward negative infinity
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
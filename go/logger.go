@@ -0,0 +1,82 @@
+package onlyfunding
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// logEvent is one line written by the JSON logger
+type logEvent struct {
+	Time          string `json:"time"`
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+	Status        int    `json:"status,omitempty"`
+	DurationMS    int64  `json:"duration_ms"`
+	Attempt       int    `json:"attempt"`
+	CorrelationID string `json:"correlation_id,omitempty"`
+	Error         string `json:"error,omitempty"`
+}
+
+// jsonLogger serializes events to w, one JSON object per line
+type jsonLogger struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// WithJSONLogger writes one JSON object per request to w, with the fields
+// time, method, path, status, duration_ms, attempt, correlation_id and error.
+// Only the URL path is logged; query strings and headers, where credentials
+// travel, never are. Writes are serialized, so w need not be safe for
+// concurrent use. Write errors are ignored.
+func WithJSONLogger(w io.Writer) Option {
+	return func(c *Client) {
+		c.jsonLogger = &jsonLogger{enc: json.NewEncoder(w)}
+	}
+}
+
+// log wraps next, logging every round trip through it
+func (l *jsonLogger) log(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		start := time.Now()
+		resp, err := next.RoundTrip(req)
+
+		event := logEvent{
+			Time:          start.UTC().Format(time.RFC3339Nano),
+			Method:        req.Method,
+			Path:          req.URL.Path,
+			DurationMS:    time.Since(start).Milliseconds(),
+			Attempt:       attemptFromContext(req.Context()),
+			CorrelationID: req.Header.Get(CorrelationIDHeader),
+		}
+		if resp != nil {
+			event.Status = resp.StatusCode
+		}
+		if err != nil {
+			event.Error = err.Error()
+		}
+
+		l.mu.Lock()
+		_ = l.enc.Encode(event)
+		l.mu.Unlock()
+		return resp, err
+	})
+}
+
+type attemptKey struct{}
+
+// withAttempt records the 1-based attempt number in ctx
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// attemptFromContext returns the attempt number stored in ctx, or 1
+func attemptFromContext(ctx context.Context) int {
+	if attempt, ok := ctx.Value(attemptKey{}).(int); ok {
+		return attempt
+	}
+	return 1
+}
@@ -0,0 +1,69 @@
+package onlyfunding
+
+// MergeFundingRates unions several snapshots, e.g. fetched from different
+// regional endpoints, into a new one. Conflicts are resolved last-writer-wins
+// in argument order: a rate, OI ranking, display name, default OI rank or
+// timestamp from a later snapshot replaces the same key from an earlier one.
+// Symbol and exchange lists keep first-seen order. Nil snapshots are skipped
+// and the inputs are not modified.
+func MergeFundingRates(snapshots ...*FundingRatesData) *FundingRatesData {
+	merged := &FundingRatesData{
+		FundingRates: make(map[string]map[string]int),
+		OIRankings:   make(map[string]string),
+	}
+
+	symbols := make(map[string]bool)
+	exchanges := make(map[string]bool)
+	names := make(map[string]int)
+
+	for _, snapshot := range snapshots {
+		if snapshot == nil {
+			continue
+		}
+
+		for _, symbol := range snapshot.Symbols {
+			if !symbols[symbol] {
+				symbols[symbol] = true
+				merged.Symbols = append(merged.Symbols, symbol)
+			}
+		}
+
+		for _, exchange := range snapshot.Exchanges.Exchanges {
+			if !exchanges[exchange] {
+				exchanges[exchange] = true
+				merged.Exchanges.Exchanges = append(merged.Exchanges.Exchanges, exchange)
+			}
+		}
+
+		for _, info := range snapshot.Exchanges.ExchangeNames {
+			if i, ok := names[info.Name]; ok {
+				merged.Exchanges.ExchangeNames[i] = info
+				continue
+			}
+			names[info.Name] = len(merged.Exchanges.ExchangeNames)
+			merged.Exchanges.ExchangeNames = append(merged.Exchanges.ExchangeNames, info)
+		}
+
+		for exchange, rates := range snapshot.FundingRates {
+			if merged.FundingRates[exchange] == nil {
+				merged.FundingRates[exchange] = make(map[string]int, len(rates))
+			}
+			for symbol, rate := range rates {
+				merged.FundingRates[exchange][symbol] = rate
+			}
+		}
+
+		for symbol, rank := range snapshot.OIRankings {
+			merged.OIRankings[symbol] = rank
+		}
+		if snapshot.DefaultOIRank != "" {
+			merged.DefaultOIRank = snapshot.DefaultOIRank
+		}
+		if snapshot.Timestamp != "" {
+			merged.Timestamp = snapshot.Timestamp
+		}
+		merged.warnings = append(merged.warnings, snapshot.warnings...)
+	}
+
+	return merged
+}
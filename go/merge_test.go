@@ -0,0 +1,105 @@
+package onlyfunding
+
+import "testing"
+
+func TestMergeFundingRatesLastWriterWins(t *testing.T) {
+	first := &FundingRatesData{
+		Symbols: []string{"BTC", "ETH"},
+		Exchanges: ExchangesData{
+			ExchangeNames: []ExchangeInfo{
+				{Name: "binance_1_perp", Display: "Binance"},
+				{Name: "okx_1_perp", Display: "OKX"},
+			},
+			Exchanges: []string{"binance_1_perp", "okx_1_perp"},
+		},
+		FundingRates: map[string]map[string]int{
+			"binance_1_perp": {"BTC": 8, "ETH": -15},
+			"okx_1_perp":     {"BTC": 5},
+		},
+		OIRankings:    map[string]string{"BTC": "1", "ETH": "2"},
+		DefaultOIRank: "500+",
+		Timestamp:     "2024-01-15 14:30:00",
+	}
+	second := &FundingRatesData{
+		Symbols: []string{"ETH", "SOL"},
+		Exchanges: ExchangesData{
+			ExchangeNames: []ExchangeInfo{
+				{Name: "binance_1_perp", Display: "BINANCE"},
+				{Name: "bybit_1_perp", Display: "BYBIT"},
+			},
+			Exchanges: []string{"bybit_1_perp", "binance_1_perp"},
+		},
+		FundingRates: map[string]map[string]int{
+			"binance_1_perp": {"BTC": 9},
+			"bybit_1_perp":   {"SOL": 30},
+		},
+		OIRankings: map[string]string{"ETH": "3"},
+		Timestamp:  "2024-01-15 14:31:00",
+	}
+
+	merged := MergeFundingRates(first, nil, second)
+
+	rates := []struct {
+		exchange, symbol string
+		want             int
+	}{
+		{"binance_1_perp", "BTC", 9},
+		{"binance_1_perp", "ETH", -15},
+		{"okx_1_perp", "BTC", 5},
+		{"bybit_1_perp", "SOL", 30},
+	}
+	for _, r := range rates {
+		if got, ok := merged.FundingRates[r.exchange][r.symbol]; !ok || got != r.want {
+			t.Errorf("rate %s on %s = %d, %v; want %d", r.symbol, r.exchange, got, ok, r.want)
+		}
+	}
+
+	wantNames := []ExchangeInfo{
+		{Name: "binance_1_perp", Display: "BINANCE"},
+		{Name: "okx_1_perp", Display: "OKX"},
+		{Name: "bybit_1_perp", Display: "BYBIT"},
+	}
+	if len(merged.Exchanges.ExchangeNames) != len(wantNames) {
+		t.Fatalf("ExchangeNames = %v, want %v", merged.Exchanges.ExchangeNames, wantNames)
+	}
+	for i, want := range wantNames {
+		if got := merged.Exchanges.ExchangeNames[i]; got != want {
+			t.Errorf("ExchangeNames[%d] = %v, want %v", i, got, want)
+		}
+	}
+
+	if merged.Timestamp != "2024-01-15 14:31:00" {
+		t.Errorf("Timestamp = %q, want the later snapshot's", merged.Timestamp)
+	}
+	if merged.DefaultOIRank != "500+" {
+		t.Errorf("DefaultOIRank = %q, want it kept when the later snapshot has none", merged.DefaultOIRank)
+	}
+	if merged.OIRankings["ETH"] != "3" || merged.OIRankings["BTC"] != "1" {
+		t.Errorf("OIRankings = %v", merged.OIRankings)
+	}
+
+	wantSymbols := []string{"BTC", "ETH", "SOL"}
+	wantExchanges := []string{"binance_1_perp", "okx_1_perp", "bybit_1_perp"}
+	if !equalStrings(merged.Symbols, wantSymbols) {
+		t.Errorf("Symbols = %v, want %v", merged.Symbols, wantSymbols)
+	}
+	if !equalStrings(merged.Exchanges.Exchanges, wantExchanges) {
+		t.Errorf("Exchanges = %v, want %v", merged.Exchanges.Exchanges, wantExchanges)
+	}
+
+	if first.FundingRates["binance_1_perp"]["BTC"] != 8 || first.Exchanges.ExchangeNames[0].Display != "Binance" {
+		t.Error("MergeFundingRates modified its input")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
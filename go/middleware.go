@@ -0,0 +1,61 @@
+package onlyfunding
+
+import "net/http"
+
+// Middleware wraps the transport every request goes through, in the style of
+// HTTP handler middleware. Use it for logging, metrics, auth or anything else
+// that needs to see requests and responses.
+type Middleware func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip calls f(req)
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// WithMiddleware appends middleware to the client's chain. The first
+// middleware given is the outermost. Requests reach middleware with the SDK's
+// own headers already set.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *Client) {
+		c.middleware = append(c.middleware, mw...)
+	}
+}
+
+// chain wraps base, or http.DefaultTransport if nil, in the SDK's built-in
+// middleware followed by the user's
+func (c *Client) chain(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	chain := []Middleware{c.headers}
+	if c.jsonLogger != nil {
+		chain = append(chain, c.jsonLogger.log)
+	}
+	chain = append(chain, c.middleware...)
+	rt := base
+	for i := len(chain) - 1; i >= 0; i-- {
+		rt = chain[i](rt)
+	}
+	return rt
+}
+
+// headers sets the Accept, User-Agent, Accept-Encoding and correlation ID
+// headers
+func (c *Client) headers(next http.RoundTripper) http.RoundTripper {
+	return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		req = req.Clone(req.Context())
+		req.Header.Set("Accept", c.acceptContentType)
+		req.Header.Set("User-Agent", "onlyfunding-Go-SDK/1.0.0")
+		if encodings := c.acceptEncodingHeader(); encodings != "" {
+			req.Header.Set("Accept-Encoding", encodings)
+		}
+		if id := correlationID(req.Context()); id != "" {
+			req.Header.Set(CorrelationIDHeader, id)
+		}
+		return next.RoundTrip(req)
+	})
+}
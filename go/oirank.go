@@ -0,0 +1,43 @@
+package onlyfunding
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// OIRank returns a symbol's open interest rank as an integer, 1 being the
+// largest market. A symbol without a rank yields an error matching
+// ErrSymbolNotFound.
+func (d *FundingRatesData) OIRank(symbol string) (int, error) {
+	raw, ok := d.OIRankings[symbol]
+	if !ok {
+		return 0, fmt.Errorf("no OI rank for %s: %w", symbol, ErrSymbolNotFound)
+	}
+	return parseOIRank(raw)
+}
+
+// DefaultOIRankValue returns DefaultOIRank, the rank of symbols absent from
+// OIRankings, as an integer. A trailing "+", as in "500+", is dropped.
+func (d *FundingRatesData) DefaultOIRankValue() (int, error) {
+	return parseOIRank(d.DefaultOIRank)
+}
+
+// EffectiveOIRank returns symbol's open interest rank, falling back to
+// DefaultOIRank for symbols without an entry in OIRankings
+func (d *FundingRatesData) EffectiveOIRank(symbol string) (int, error) {
+	if raw, ok := d.OIRankings[symbol]; ok {
+		return parseOIRank(raw)
+	}
+	return d.DefaultOIRankValue()
+}
+
+// parseOIRank parses a rank such as "3" or "500+"
+func parseOIRank(raw string) (int, error) {
+	s := strings.TrimSuffix(strings.TrimSpace(raw), "+")
+	rank, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid OI rank %q: %w", raw, err)
+	}
+	return rank, nil
+}
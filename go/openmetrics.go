@@ -0,0 +1,73 @@
+package onlyfunding
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// WriteOpenMetrics writes d's rates and arbitrage spreads in the OpenMetrics
+// text format, for serving from a scrape endpoint without a Prometheus client
+// dependency. It emits two gauges, in decimal units:
+//
+//	onlyfunding_funding_rate{exchange, symbol}
+//	onlyfunding_arbitrage_spread{symbol, long_exchange, short_exchange}
+//
+// Series are sorted so the output is stable between scrapes. Spreads cover
+// every exchange pair with a non-zero spread; no client scan options apply.
+func (d *FundingRatesData) WriteOpenMetrics(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "# HELP onlyfunding_funding_rate Funding rate per interval, as a decimal.")
+	fmt.Fprintln(bw, "# TYPE onlyfunding_funding_rate gauge")
+	exchanges := make([]string, 0, len(d.FundingRates))
+	for exchange := range d.FundingRates {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Strings(exchanges)
+	for _, exchange := range exchanges {
+		symbols := make([]string, 0, len(d.FundingRates[exchange]))
+		for symbol := range d.FundingRates[exchange] {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+		for _, symbol := range symbols {
+			fmt.Fprintf(bw, "onlyfunding_funding_rate{exchange=\"%s\",symbol=\"%s\"} %s\n",
+				escapeLabel(exchange), escapeLabel(symbol),
+				formatDecimal(float64(d.FundingRates[exchange][symbol])/10000.0, -1))
+		}
+	}
+
+	fmt.Fprintln(bw, "# HELP onlyfunding_arbitrage_spread Spread between two exchanges' funding rates, as a decimal.")
+	fmt.Fprintln(bw, "# TYPE onlyfunding_arbitrage_spread gauge")
+	var opps []ArbitrageOpportunity
+	for _, symbol := range d.Symbols {
+		opps = append(opps, d.arbitrageOpportunities(symbol, 0, &scanConfig{})...)
+	}
+	sort.Slice(opps, func(i, j int) bool {
+		if opps[i].Symbol != opps[j].Symbol {
+			return opps[i].Symbol < opps[j].Symbol
+		}
+		if opps[i].LongExchange != opps[j].LongExchange {
+			return opps[i].LongExchange < opps[j].LongExchange
+		}
+		return opps[i].ShortExchange < opps[j].ShortExchange
+	})
+	for _, opp := range opps {
+		fmt.Fprintf(bw, "onlyfunding_arbitrage_spread{symbol=\"%s\",long_exchange=\"%s\",short_exchange=\"%s\"} %s\n",
+			escapeLabel(opp.Symbol), escapeLabel(opp.LongExchange), escapeLabel(opp.ShortExchange),
+			formatDecimal(opp.Spread, -1))
+	}
+
+	fmt.Fprintln(bw, "# EOF")
+	return bw.Flush()
+}
+
+// labelEscaper escapes a label value per the OpenMetrics text format
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
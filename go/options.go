@@ -0,0 +1,169 @@
+package onlyfunding
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithMaxIdleConns sets how many idle keep-alive connections the client keeps
+// open to the API. The SDK talks to a single host, so the limit applies both
+// overall and per host. For a poller, 2-4 is plenty to avoid reconnecting on
+// every request.
+func WithMaxIdleConns(n int) Option {
+	return func(c *Client) {
+		c.maxIdleConns = n
+	}
+}
+
+// WithIdleConnTimeout sets how long an idle connection is kept before it is
+// closed. Set it above your polling interval (e.g. 90s for a 60s poller) so
+// the connection is reused between polls instead of re-established.
+func WithIdleConnTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.idleConnTimeout = d
+	}
+}
+
+// WithKeepAlive sets the TCP keep-alive probe interval for new connections.
+// 30s is a sensible value for long-lived pollers.
+func WithKeepAlive(d time.Duration) Option {
+	return func(c *Client) {
+		c.keepAlive = d
+	}
+}
+
+// httpVersion selects the protocols the transport may negotiate
+type httpVersion int
+
+const (
+	httpAuto httpVersion = iota
+	httpForce2
+	httpOnly1
+)
+
+// WithForceHTTP2 makes the transport attempt HTTP/2. That is already the
+// default: Go's transport, and the SDK's when other options customize it,
+// negotiate HTTP/2 where the server supports it. The option states the choice
+// explicitly and overrides an earlier WithDisableHTTP2, the last of the two
+// winning. HTTP/2 is negotiated via TLS ALPN, so it only applies to https://
+// base URLs; plaintext http:// endpoints always use HTTP/1.1. Check
+// Response.Proto (e.g. from a middleware) to confirm what was negotiated.
+func WithForceHTTP2() Option {
+	return func(c *Client) {
+		c.httpVersion = httpForce2
+	}
+}
+
+// WithDisableHTTP2 restricts the transport to HTTP/1.1
+func WithDisableHTTP2() Option {
+	return func(c *Client) {
+		c.httpVersion = httpOnly1
+	}
+}
+
+// WithRoundTripper sends requests through rt instead of a network transport,
+// for example a stub returning canned responses in tests. The SDK's headers
+// and any middleware still wrap rt. The connection and HTTP version options
+// are ignored when it is set, since they only configure the built-in
+// transport.
+func WithRoundTripper(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.roundTripper = rt
+	}
+}
+
+// WithHTTPClient sends requests through a copy of hc, keeping its timeout,
+// transport, cookie jar and redirect policy, for TLS, proxy or pooling
+// settings the other options don't cover. The SDK's headers and any
+// middleware wrap hc's transport, or http.DefaultTransport if it has none,
+// while hc itself is left untouched. The client timeout passed to
+// NewClientWithOptions is replaced by hc.Timeout, and the connection and HTTP
+// version options are ignored. WithRoundTripper, if also given, takes the
+// place of hc's transport.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+	}
+}
+
+// baseTransport returns the transport beneath the SDK's middleware: the one
+// from WithRoundTripper, else the WithHTTPClient client's, else newTransport's
+func (c *Client) baseTransport() http.RoundTripper {
+	if c.httpClient != nil && c.roundTripper == nil {
+		return c.httpClient.Transport
+	}
+	return c.newTransport()
+}
+
+// newHTTPClient returns a client sending through transport, configured like
+// the WithHTTPClient client if there is one
+func (c *Client) newHTTPClient(transport http.RoundTripper) *http.Client {
+	if c.httpClient == nil {
+		return &http.Client{Timeout: c.timeout, Transport: transport}
+	}
+	hc := *c.httpClient
+	hc.Transport = transport
+	return &hc
+}
+
+// newTransport returns the transport requests are sent through: the one given
+// to WithRoundTripper, one tuned by the connection options, or nil to use
+// http.DefaultTransport when neither was given
+func (c *Client) newTransport() http.RoundTripper {
+	if c.roundTripper != nil {
+		return c.roundTripper
+	}
+	if c.maxIdleConns == 0 && c.idleConnTimeout == 0 && c.keepAlive == 0 && c.httpVersion == httpAuto {
+		return nil
+	}
+
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	if c.maxIdleConns > 0 {
+		t.MaxIdleConns = c.maxIdleConns
+		t.MaxIdleConnsPerHost = c.maxIdleConns
+	}
+	if c.idleConnTimeout > 0 {
+		t.IdleConnTimeout = c.idleConnTimeout
+	}
+	if c.keepAlive > 0 {
+		dialer := &net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: c.keepAlive,
+		}
+		t.DialContext = dialer.DialContext
+	}
+	switch c.httpVersion {
+	case httpForce2:
+		t.ForceAttemptHTTP2 = true
+	case httpOnly1:
+		t.ForceAttemptHTTP2 = false
+		t.TLSNextProto = make(map[string]func(string, *tls.Conn) http.RoundTripper)
+	}
+	return t
+}
+
+// WithAcceptContentType sets the media type requested via the Accept header.
+// The response is decoded according to the Content-Type the server actually
+// returns, so asking for a format the server doesn't offer is harmless.
+// Defaults to application/json.
+func WithAcceptContentType(contentType string) Option {
+	return func(c *Client) {
+		c.acceptContentType = contentType
+	}
+}
+
+func (v httpVersion) String() string {
+	switch v {
+	case httpForce2:
+		return "http2"
+	case httpOnly1:
+		return "http1"
+	default:
+		return "auto"
+	}
+}
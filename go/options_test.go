@@ -0,0 +1,62 @@
+package onlyfunding
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHTTPVersionOptions(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.Write(ExampleFundingRatesJSON())
+	}))
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	defer srv.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	tests := []struct {
+		name string
+		opts []Option
+		want int
+	}{
+		{"force", []Option{WithForceHTTP2()}, 2},
+		{"disable", []Option{WithDisableHTTP2()}, 1},
+		{"force after disable", []Option{WithDisableHTTP2(), WithForceHTTP2()}, 2},
+		{"tuned", []Option{WithMaxIdleConns(2)}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var protoMajor int
+			capture := func(next http.RoundTripper) http.RoundTripper {
+				return RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+					resp, err := next.RoundTrip(req)
+					if err == nil {
+						protoMajor = resp.ProtoMajor
+					}
+					return resp, err
+				})
+			}
+			c := NewClientWithOptions(srv.URL, 5*time.Second, append(tt.opts, WithMiddleware(capture))...)
+
+			// trust the test server's certificate on the transport the
+			// options built
+			transport := c.newTransport().(*http.Transport)
+			transport.TLSClientConfig = &tls.Config{RootCAs: roots}
+			c.client.Transport = c.chain(transport)
+
+			if _, err := c.GetFundingRates(); err != nil {
+				t.Fatalf("GetFundingRates error: %v", err)
+			}
+			if protoMajor != tt.want {
+				t.Errorf("ProtoMajor = %d, want %d", protoMajor, tt.want)
+			}
+		})
+	}
+}
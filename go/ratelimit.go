@@ -0,0 +1,117 @@
+package onlyfunding
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// RateLimitState holds the rate-limit hints from the most recent API
+// response. Fields the server didn't send are zero.
+type RateLimitState struct {
+	// Limit and Remaining are the request quota and what is left of it, from
+	// X-RateLimit-Limit and X-RateLimit-Remaining
+	Limit     int
+	Remaining int
+	// Reset is when the quota refills, from X-RateLimit-Reset
+	Reset time.Time
+	// RetryAfter is how long the server asked clients to wait, from
+	// Retry-After, typically sent with a 429 or 503
+	RetryAfter time.Duration
+	// UpdatedAt is when the response carrying these hints was received
+	UpdatedAt time.Time
+}
+
+// Wait returns how long a poller should hold off at now before its next
+// request: the remaining Retry-After delay, or the time until Reset once the
+// quota is used up. It returns 0 when the server gave no reason to wait.
+func (s RateLimitState) Wait(now time.Time) time.Duration {
+	var wait time.Duration
+	if s.RetryAfter > 0 {
+		wait = s.UpdatedAt.Add(s.RetryAfter).Sub(now)
+	}
+	if s.Limit > 0 && s.Remaining == 0 && !s.Reset.IsZero() {
+		if untilReset := s.Reset.Sub(now); untilReset > wait {
+			wait = untilReset
+		}
+	}
+	if wait < 0 {
+		return 0
+	}
+	return wait
+}
+
+// RateLimitState returns the rate-limit hints from the last response the
+// client received, so pollers can back off when the server is busy
+func (c *Client) RateLimitState() RateLimitState {
+	c.lastResponseMu.Lock()
+	defer c.lastResponseMu.Unlock()
+	return c.rateLimit
+}
+
+// LastResponseHeaders returns a copy of the headers of the last response the
+// client received, or nil before the first one. Metadata such as server time
+// and cache status is only available there.
+func (c *Client) LastResponseHeaders() http.Header {
+	c.lastResponseMu.Lock()
+	defer c.lastResponseMu.Unlock()
+	return c.lastHeaders.Clone()
+}
+
+// recordResponse keeps the headers and rate-limit hints of resp for
+// LastResponseHeaders and RateLimitState
+func (c *Client) recordResponse(resp *http.Response) {
+	now := time.Now()
+	state := RateLimitState{
+		Limit:      headerInt(resp.Header, "X-RateLimit-Limit"),
+		Remaining:  headerInt(resp.Header, "X-RateLimit-Remaining"),
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), now),
+		UpdatedAt:  now,
+	}
+	if reset := headerInt(resp.Header, "X-RateLimit-Reset"); reset > 0 {
+		state.Reset = resetTime(reset, now)
+	}
+
+	headers := resp.Header.Clone()
+
+	c.lastResponseMu.Lock()
+	c.rateLimit = state
+	c.lastHeaders = headers
+	c.lastResponseMu.Unlock()
+}
+
+// headerInt parses an integer header, returning 0 if absent or malformed
+func headerInt(h http.Header, key string) int {
+	n, err := strconv.Atoi(h.Get(key))
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// parseRetryAfter parses a Retry-After value given either as seconds or as
+// an HTTP date
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0
+		}
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+// resetTime interprets X-RateLimit-Reset, which servers send either as a Unix
+// timestamp or as seconds from now
+func resetTime(v int, now time.Time) time.Time {
+	if v > 1e9 {
+		return time.Unix(int64(v), 0)
+	}
+	return now.Add(time.Duration(v) * time.Second)
+}
@@ -0,0 +1,175 @@
+package onlyfunding
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultRecorderBuffer is the number of snapshots a Recorder queues when
+// NewRecorder is given a non-positive buffer size
+const DefaultRecorderBuffer = 64
+
+// RecorderPolicy decides what a Recorder does when its writer falls behind
+// and the queue is full
+type RecorderPolicy int
+
+const (
+	// RecorderDrop discards the snapshot and counts it in Dropped
+	RecorderDrop RecorderPolicy = iota
+	// RecorderError rejects the snapshot with ErrRecorderFull, which Close
+	// also reports
+	RecorderError
+)
+
+var (
+	// ErrRecorderFull is returned by Record under RecorderError when the
+	// queue is full
+	ErrRecorderFull = errors.New("recorder queue full")
+	// ErrRecorderClosed is returned by Record after Close
+	ErrRecorderClosed = errors.New("recorder closed")
+)
+
+// RecordedSnapshot is one line of a recording
+type RecordedSnapshot struct {
+	RecordedAt time.Time         `json:"recorded_at"`
+	Data       *FundingRatesData `json:"data"`
+}
+
+// Recorder appends snapshots to a writer as newline-delimited JSON. Writes
+// happen on a background goroutine through a buffer that is flushed whenever
+// the queue drains, so Record never blocks on a slow writer.
+type Recorder struct {
+	dropped int64 // first for 64-bit atomic alignment
+
+	policy RecorderPolicy
+	queue  chan []byte
+	done   chan struct{}
+
+	mu     sync.RWMutex
+	closed bool
+
+	errMu sync.Mutex
+	err   error
+}
+
+// NewRecorder starts a recorder writing to w. Call Close to flush and stop it.
+func NewRecorder(w io.Writer, bufferSize int, policy RecorderPolicy) *Recorder {
+	if bufferSize <= 0 {
+		bufferSize = DefaultRecorderBuffer
+	}
+	r := &Recorder{
+		policy: policy,
+		queue:  make(chan []byte, bufferSize),
+		done:   make(chan struct{}),
+	}
+	go r.run(bufio.NewWriter(w))
+	return r
+}
+
+// WithRecorder records every snapshot the client fetches to r
+func WithRecorder(r *Recorder) Option {
+	return func(c *Client) {
+		c.recorder = r
+	}
+}
+
+// Record queues a snapshot taken now
+func (r *Recorder) Record(data *FundingRatesData) error {
+	line, err := json.Marshal(RecordedSnapshot{RecordedAt: time.Now(), Data: data})
+	if err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return ErrRecorderClosed
+	}
+
+	select {
+	case r.queue <- line:
+		return nil
+	default:
+	}
+
+	if r.policy == RecorderError {
+		r.setErr(ErrRecorderFull)
+		return ErrRecorderFull
+	}
+	atomic.AddInt64(&r.dropped, 1)
+	return nil
+}
+
+// Dropped returns how many snapshots were discarded under RecorderDrop
+func (r *Recorder) Dropped() int64 {
+	return atomic.LoadInt64(&r.dropped)
+}
+
+// Close writes out the queued snapshots, flushes and stops the recorder. It
+// returns the first write error, or ErrRecorderFull if a snapshot was
+// rejected.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	if !r.closed {
+		r.closed = true
+		close(r.queue)
+	}
+	r.mu.Unlock()
+
+	<-r.done
+
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) run(w *bufio.Writer) {
+	defer close(r.done)
+
+	for line := range r.queue {
+		if _, err := w.Write(append(line, '\n')); err != nil {
+			r.setErr(err)
+			continue
+		}
+		if len(r.queue) == 0 {
+			if err := w.Flush(); err != nil {
+				r.setErr(err)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		r.setErr(err)
+	}
+}
+
+func (r *Recorder) setErr(err error) {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+// RecordingReader replays a recording written by a Recorder
+type RecordingReader struct {
+	dec *json.Decoder
+}
+
+// NewRecordingReader reads recorded snapshots from r
+func NewRecordingReader(r io.Reader) *RecordingReader {
+	return &RecordingReader{dec: json.NewDecoder(r)}
+}
+
+// Next returns the next recorded snapshot, or io.EOF at the end
+func (r *RecordingReader) Next() (RecordedSnapshot, error) {
+	var snapshot RecordedSnapshot
+	if err := r.dec.Decode(&snapshot); err != nil {
+		return RecordedSnapshot{}, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,200 @@
+package onlyfunding
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"time"
+)
+
+// WithRetry retries failed requests up to maxAttempts attempts in total,
+// waiting with exponential backoff from baseDelay between them (see
+// BackoffSchedule). Each delay is jittered to between half and all of its
+// nominal value, so clients that failed together don't retry in lockstep.
+//
+// Only transient failures are retried: 5xx responses, connection errors and
+// bodies cut off mid-read. 4xx responses and malformed bodies are permanent
+// and returned at once, as are timeouts unless WithRetryOnTimeout is set. The
+// wait between attempts ends early if the request context is done. When every
+// attempt fails, the last error is returned wrapped. Each attempt gets the
+// client timeout and attempt timeout afresh; bound the whole operation with a
+// context deadline. A maxAttempts of 1 or less disables retries, the default.
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(c *Client) {
+		c.maxAttempts = maxAttempts
+		c.baseDelay = baseDelay
+	}
+}
+
+// WithRetryHook calls fn before each retry with the number of the attempt
+// that failed, its error and the delay before the next one. Use it to log or
+// count retries.
+func WithRetryHook(fn func(attempt int, err error, delay time.Duration)) Option {
+	return func(c *Client) {
+		c.retryHook = fn
+	}
+}
+
+// retryable reports whether a request that failed with err is worth retrying
+func (c *Client) retryable(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode >= 500
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	if isTimeout(err) {
+		return c.retryOnTimeout
+	}
+	if isReadError(err) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// maxBackoffDelay caps the delay between two attempts
+const maxBackoffDelay = 30 * time.Second
+
+// BackoffSchedule returns the nominal delay before each retry under the
+// client's retry settings, without jitter: the base delay doubling after
+// every attempt, capped at 30s. It has one entry per retry, so it is empty
+// when retries are disabled, which is the default. The sum is the worst-case
+// time spent waiting, on top of the attempts themselves.
+func (c *Client) BackoffSchedule() []time.Duration {
+	if c.maxAttempts <= 1 {
+		return nil
+	}
+	schedule := make([]time.Duration, c.maxAttempts-1)
+	for i := range schedule {
+		schedule[i] = c.backoff(i + 1)
+	}
+	return schedule
+}
+
+// backoff returns the nominal delay after the given 1-based attempt
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.baseDelay
+	for i := 1; i < attempt && delay < maxBackoffDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoffDelay {
+		return maxBackoffDelay
+	}
+	return delay
+}
+
+// WithRetryOnTimeout controls whether a request that timed out, either by
+// hitting a context deadline or the client timeout, counts as retryable.
+// Timeouts are not retried by default since retrying a slow API usually
+// compounds the slowness. Connection errors and 5xx responses are classified
+// separately and are unaffected by this setting.
+func WithRetryOnTimeout(retry bool) Option {
+	return func(c *Client) {
+		c.retryOnTimeout = retry
+	}
+}
+
+// isTimeout reports whether err is a context deadline or a network timeout
+func isTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// WithAttemptTimeout caps each individual request attempt at d, independently
+// of the client timeout and the caller's context. Each attempt runs under its
+// own context.WithTimeout derived from the caller's context, so a single slow
+// attempt fails with a timeout instead of consuming the whole budget.
+//
+// The three limits nest: an attempt ends at the earliest of its own timeout,
+// the client timeout and the parent context deadline. With retries enabled the
+// parent deadline bounds the whole operation, so at most roughly
+// deadline/attempt-timeout attempts fit regardless of the retry count, and a
+// timed-out attempt is only retried when WithRetryOnTimeout is set. A zero or
+// negative d disables the per-attempt cap, which is the default.
+func WithAttemptTimeout(d time.Duration) Option {
+	return func(c *Client) {
+		c.attemptTimeout = d
+	}
+}
+
+// attemptContext derives the context for a single attempt from ctx
+func (c *Client) attemptContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.attemptTimeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.attemptTimeout)
+}
+
+// readError marks a failure to read a response body, such as a connection
+// dropped mid-stream. Unlike a malformed body it is worth retrying, since the
+// whole request can simply be re-issued.
+type readError struct {
+	err error
+}
+
+func (e *readError) Error() string { return e.err.Error() }
+func (e *readError) Unwrap() error { return e.err }
+
+// isReadError reports whether err came from a truncated or interrupted body
+func isReadError(err error) bool {
+	var re *readError
+	return errors.As(err, &re)
+}
+
+// trackingReader remembers the first error other than io.EOF its reader
+// returned, so a decode failure caused by the transport can be told apart
+// from one caused by the content
+type trackingReader struct {
+	r   io.Reader
+	err error
+}
+
+func (t *trackingReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if err != nil && err != io.EOF && t.err == nil {
+		t.err = err
+	}
+	return n, err
+}
+
+// WithRandSource sets the source used to jitter retry backoff delays.
+// Injecting a seeded source makes the delays reproducible in tests. Defaults
+// to a time-seeded source; a nil src keeps that default.
+func WithRandSource(src rand.Source) Option {
+	return func(c *Client) {
+		if src == nil {
+			c.rand = nil
+			return
+		}
+		c.rand = rand.New(src)
+	}
+}
+
+// jitter returns a random duration in [0, d)
+func (c *Client) jitter(d time.Duration) time.Duration {
+	if d <= 0 {
+		return 0
+	}
+	c.randMu.Lock()
+	defer c.randMu.Unlock()
+	return time.Duration(c.rand.Int63n(int64(d)))
+}
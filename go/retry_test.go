@@ -0,0 +1,198 @@
+package onlyfunding
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// truncatingServer serves the example response, cutting the body short of its
+// Content-Length for the first truncate requests
+func truncatingServer(t *testing.T, truncate int32) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	body := ExampleFundingRatesJSON()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		if n <= truncate {
+			w.Write(body[:len(body)/2])
+			return
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestTruncatedBodyIsReadError(t *testing.T) {
+	srv, hits := truncatingServer(t, 1)
+	c := NewClientWithOptions(srv.URL, 5*time.Second)
+
+	_, err := c.GetFundingRates()
+	if err == nil {
+		t.Fatal("GetFundingRates() succeeded on a truncated body")
+	}
+	if !isReadError(err) {
+		t.Errorf("isReadError(%v) = false, want true", err)
+	}
+	if !c.retryable(err) {
+		t.Errorf("retryable(%v) = false, want true", err)
+	}
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+}
+
+func TestRetryReissuesTruncatedRequest(t *testing.T) {
+	srv, hits := truncatingServer(t, 1)
+	var retries []int
+	c := NewClientWithOptions(srv.URL, 5*time.Second,
+		WithRetry(3, time.Millisecond),
+		WithRetryHook(func(attempt int, err error, delay time.Duration) {
+			if !isReadError(err) {
+				t.Errorf("attempt %d failed with %v, want a read error", attempt, err)
+			}
+			retries = append(retries, attempt)
+		}))
+
+	data, err := c.GetFundingRates()
+	if err != nil {
+		t.Fatalf("GetFundingRates() error: %v", err)
+	}
+	if len(data.Symbols) == 0 {
+		t.Error("GetFundingRates() returned no symbols")
+	}
+	if got := atomic.LoadInt32(hits); got != 2 {
+		t.Errorf("server saw %d requests, want 2", got)
+	}
+	if len(retries) != 1 || retries[0] != 1 {
+		t.Errorf("retries = %v, want [1]", retries)
+	}
+}
+
+func TestRetriesShareCorrelationID(t *testing.T) {
+	var mu sync.Mutex
+	var ids []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		ids = append(ids, r.Header.Get(CorrelationIDHeader))
+		mu.Unlock()
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c := NewClientWithOptions(srv.URL, 5*time.Second, WithRetry(2, time.Millisecond))
+
+	for call := 0; call < 2; call++ {
+		if _, err := c.GetFundingRates(); err == nil {
+			t.Fatal("GetFundingRates() succeeded against a failing server")
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(ids) != 4 {
+		t.Fatalf("server saw %d requests, want 4", len(ids))
+	}
+	if ids[0] == "" || ids[0] != ids[1] || ids[2] != ids[3] {
+		t.Errorf("correlation IDs = %q, want one per call shared by its attempts", ids)
+	}
+	if ids[0] == ids[2] {
+		t.Errorf("two calls both sent correlation ID %q", ids[0])
+	}
+}
+
+// statusServer answers every request with status and counts them
+func statusServer(t *testing.T, status int) (*httptest.Server, *int32) {
+	t.Helper()
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.Error(w, http.StatusText(status), status)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &hits
+}
+
+func TestRetryPolicy(t *testing.T) {
+	const maxAttempts = 3
+	tests := []struct {
+		name         string
+		status       int
+		wantAttempts int32
+	}{
+		{"bad request", http.StatusBadRequest, 1},
+		{"not found", http.StatusNotFound, 1},
+		{"too many requests", http.StatusTooManyRequests, 1},
+		{"internal error", http.StatusInternalServerError, maxAttempts},
+		{"bad gateway", http.StatusBadGateway, maxAttempts},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, hits := statusServer(t, tt.status)
+			var retries []int
+			c := NewClientWithOptions(srv.URL, 5*time.Second,
+				WithRetry(maxAttempts, time.Millisecond),
+				WithRetryHook(func(attempt int, err error, delay time.Duration) {
+					retries = append(retries, attempt)
+				}))
+
+			_, err := c.GetFundingRates()
+			if got := atomic.LoadInt32(hits); got != tt.wantAttempts {
+				t.Errorf("server saw %d requests, want %d", got, tt.wantAttempts)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Fatalf("error = %v, want an APIError with status %d", err, tt.status)
+			}
+			if wrapped := errors.Unwrap(err) != nil; wrapped != (tt.wantAttempts > 1) {
+				t.Errorf("error %q wrapped = %v, want %v", err, wrapped, tt.wantAttempts > 1)
+			}
+			for i, attempt := range retries {
+				if attempt != i+1 {
+					t.Fatalf("retry hook saw attempts %v, want 1..%d", retries, tt.wantAttempts-1)
+				}
+			}
+			if len(retries) != int(tt.wantAttempts)-1 {
+				t.Errorf("retry hook saw attempts %v, want 1..%d", retries, tt.wantAttempts-1)
+			}
+		})
+	}
+}
+
+func TestRetryBackoffStopsOnCancel(t *testing.T) {
+	srv, hits := statusServer(t, http.StatusServiceUnavailable)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c := NewClientWithOptions(srv.URL, 5*time.Second,
+		WithRetry(3, time.Minute),
+		WithRetryHook(func(attempt int, err error, delay time.Duration) {
+			cancel()
+		}))
+
+	start := time.Now()
+	_, err := c.GetFundingRatesContext(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("error = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancelled call took %v, want it to skip the backoff", elapsed)
+	}
+	if got := atomic.LoadInt32(hits); got != 1 {
+		t.Errorf("server saw %d requests, want 1", got)
+	}
+}
+
+func TestWithRandSourceNilKeepsDefault(t *testing.T) {
+	c := NewClientWithOptions(DefaultBaseURL, time.Second, WithRandSource(nil))
+	if d := c.jitter(time.Second); d < 0 || d >= time.Second {
+		t.Errorf("jitter(1s) = %v, want within [0, 1s)", d)
+	}
+}
@@ -0,0 +1,234 @@
+package onlyfunding
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// EndpointStatus is the latency router's view of one endpoint
+type EndpointStatus struct {
+	URL string
+	// Latency is the time to response headers measured by the last
+	// successful probe, or 0 if none has succeeded yet
+	Latency time.Duration
+	// Healthy is false after a failed probe or request, until a probe
+	// succeeds again
+	Healthy bool
+	// LastProbe is when the endpoint was last probed
+	LastProbe time.Time
+}
+
+// latencyRouter picks the fastest healthy endpoint, re-probing them all in
+// the background
+type latencyRouter struct {
+	interval time.Duration
+
+	mu        sync.Mutex
+	endpoints []EndpointStatus
+
+	// client sends probes, bypassing user middleware and logging
+	client *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// WithLatencyRouting sends requests to whichever of endpoints, base URLs of
+// regional API deployments, answered fastest. Every endpoint is probed in
+// parallel right away and then every probeInterval in the background; call
+// Client.Close to stop probing. An endpoint whose probe or request fails is
+// skipped in favor of the next fastest until a probe succeeds again. Until the
+// first probes finish, and whenever every endpoint is unhealthy, endpoints are
+// tried in the order given. The base URL passed to NewClientWithOptions is
+// ignored. A non-positive probeInterval probes only once, so an endpoint
+// marked unhealthy by a failed request is never restored and stays behind the
+// healthy ones for the life of the client.
+func WithLatencyRouting(endpoints []string, probeInterval time.Duration) Option {
+	return func(c *Client) {
+		if len(endpoints) == 0 {
+			c.router = nil
+			return
+		}
+		r := &latencyRouter{
+			interval:  probeInterval,
+			endpoints: make([]EndpointStatus, len(endpoints)),
+		}
+		for i, endpoint := range endpoints {
+			r.endpoints[i] = EndpointStatus{URL: endpoint, Healthy: true}
+		}
+		c.router = r
+	}
+}
+
+// endpoint returns the base URL the next request should go to
+func (c *Client) endpoint() string {
+	if c.router == nil {
+		return c.baseURL
+	}
+	return c.router.current()
+}
+
+// RoutingState returns the latency router's endpoints, the one requests
+// currently go to first, or nil without WithLatencyRouting
+func (c *Client) RoutingState() []EndpointStatus {
+	if c.router == nil {
+		return nil
+	}
+	return c.router.ranked()
+}
+
+// Close stops the client's background work, such as latency probing,
+// cancelling probes in flight and waiting for them to return. The client can
+// still make requests afterwards.
+func (c *Client) Close() error {
+	if c.router != nil {
+		c.router.cancel()
+		c.router.wg.Wait()
+	}
+	return nil
+}
+
+// probeChain wraps base, or http.DefaultTransport if nil, in the SDK's
+// headers only. Probes are not user traffic, so they skip user middleware and
+// the JSON logger.
+func (c *Client) probeChain(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return c.headers(base)
+}
+
+// current returns the URL of the best endpoint
+func (r *latencyRouter) current() string {
+	return r.ranked()[0].URL
+}
+
+// ranked returns the endpoints best first: healthy before unhealthy, probed
+// before unprobed, then by latency, keeping the configured order for ties
+func (r *latencyRouter) ranked() []EndpointStatus {
+	r.mu.Lock()
+	ranked := append([]EndpointStatus(nil), r.endpoints...)
+	r.mu.Unlock()
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Healthy != b.Healthy {
+			return a.Healthy
+		}
+		if probedA, probedB := a.Latency > 0, b.Latency > 0; probedA != probedB {
+			return probedA
+		}
+		return a.Latency < b.Latency
+	})
+	return ranked
+}
+
+// markFailed marks url unhealthy after a failed request
+func (r *latencyRouter) markFailed(url string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := range r.endpoints {
+		if r.endpoints[i].URL == url {
+			r.endpoints[i].Healthy = false
+		}
+	}
+}
+
+// start begins probing in the background, sending probes with client
+func (r *latencyRouter) start(c *Client, client *http.Client) {
+	r.client = client
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		r.run(c)
+	}()
+}
+
+// run probes the endpoints now and then every interval until stopped
+func (r *latencyRouter) run(c *Client) {
+	r.probeAll(c)
+	if r.interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			r.probeAll(c)
+		}
+	}
+}
+
+// probeAll probes every endpoint in parallel and records the results
+func (r *latencyRouter) probeAll(c *Client) {
+	r.mu.Lock()
+	urls := make([]string, len(r.endpoints))
+	for i, endpoint := range r.endpoints {
+		urls[i] = endpoint.URL
+	}
+	r.mu.Unlock()
+
+	results := make([]EndpointStatus, len(urls))
+	var wg sync.WaitGroup
+	for i, url := range urls {
+		wg.Add(1)
+		go func(i int, url string) {
+			defer wg.Done()
+			latency, err := r.probe(c, url)
+			results[i] = EndpointStatus{URL: url, Latency: latency, Healthy: err == nil, LastProbe: time.Now()}
+		}(i, url)
+	}
+	wg.Wait()
+
+	if r.ctx.Err() != nil {
+		// probes cut short by Close say nothing about the endpoints
+		return
+	}
+
+	r.mu.Lock()
+	for i := range r.endpoints {
+		if !results[i].Healthy {
+			// keep the last good latency so a recovered endpoint ranks
+			// sensibly, but take it out of rotation
+			results[i].Latency = r.endpoints[i].Latency
+		}
+		r.endpoints[i] = results[i]
+	}
+	r.mu.Unlock()
+}
+
+// probe measures how long endpoint takes to answer with response headers
+func (r *latencyRouter) probe(c *Client, endpoint string) (time.Duration, error) {
+	ctx, cancel := c.requestContext(r.ctx)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/funding", endpoint), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	start := time.Now()
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	latency := time.Since(start)
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("probe failed: %d %s", resp.StatusCode, resp.Status)
+	}
+	return latency, nil
+}
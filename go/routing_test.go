@@ -0,0 +1,96 @@
+package onlyfunding
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCloseWaitsForProbesInFlight(t *testing.T) {
+	started := make(chan struct{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case started <- struct{}{}:
+		default:
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer srv.Close()
+
+	var inFlight int32
+	tracking := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	c := NewClientWithOptions("", time.Minute,
+		WithRoundTripper(tracking),
+		WithLatencyRouting([]string{srv.URL}, time.Hour))
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("probe never reached the server")
+	}
+
+	start := time.Now()
+	c.Close()
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Close took %v, want the probe cancelled", elapsed)
+	}
+	if n := atomic.LoadInt32(&inFlight); n != 0 {
+		t.Errorf("%d probes still in flight after Close", n)
+	}
+	if state := c.RoutingState(); !state[0].LastProbe.IsZero() {
+		t.Errorf("cancelled probe recorded as %+v", state[0])
+	}
+}
+
+func TestProbesSkipMiddlewareAndLogger(t *testing.T) {
+	var userAgent atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent.Store(r.Header.Get("User-Agent"))
+		w.Header().Set("Content-Type", contentTypeJSON)
+		w.Write(ExampleFundingRatesJSON())
+	}))
+	defer srv.Close()
+
+	var requests int32
+	var log bytes.Buffer
+	c := NewClientWithOptions("", time.Second,
+		WithMiddleware(countRequests(&requests)),
+		WithJSONLogger(&log),
+		WithLatencyRouting([]string{srv.URL}, 0))
+
+	deadline := time.Now().Add(5 * time.Second)
+	for c.RoutingState()[0].LastProbe.IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("probe never finished")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	c.Close()
+
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Errorf("middleware saw %d probes", n)
+	}
+	if log.Len() != 0 {
+		t.Errorf("probe logged: %s", log.String())
+	}
+	if ua, _ := userAgent.Load().(string); ua == "" {
+		t.Error("probe sent without the SDK's headers")
+	}
+
+	if _, err := c.GetFundingRates(); err != nil {
+		t.Fatalf("GetFundingRates error: %v", err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("middleware saw %d requests, want 1", n)
+	}
+}
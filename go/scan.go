@@ -0,0 +1,400 @@
+package onlyfunding
+
+import (
+	"container/heap"
+	"context"
+	"sort"
+	"time"
+)
+
+// scanConfig holds the client options that shape arbitrage scans. The zero
+// value applies no restrictions.
+type scanConfig struct {
+	allowedPairs      map[[2]string]bool
+	minOIRank         int
+	includeUnranked   bool
+	minExchanges      int
+	sortKey           SortKey
+	includeZeroSpread bool
+	reconciliation    ExchangeReconciliation
+	hourly            bool
+	interval          func(exchange string) time.Duration
+}
+
+// SortKey selects the value all-symbol scans rank opportunities by
+type SortKey int
+
+const (
+	// SortBySpread ranks by the raw Spread
+	SortBySpread SortKey = iota
+	// SortByAnnualizedSpread ranks by Client.AnnualizedSpread, which accounts
+	// for exchanges funding over different intervals
+	SortByAnnualizedSpread
+)
+
+func (k SortKey) String() string {
+	if k == SortByAnnualizedSpread {
+		return "annualized_spread"
+	}
+	return "spread"
+}
+
+// WithAllowedPairs restricts arbitrage scans to opportunities between the
+// given exchange pairs, e.g. the venues you hold accounts on together. Pair
+// order doesn't matter: {A, B} also allows {B, A}. A nil or empty list lifts
+// the restriction, allowing every pair.
+func WithAllowedPairs(pairs [][2]string) Option {
+	return func(c *Client) {
+		if len(pairs) == 0 {
+			c.scan.allowedPairs = nil
+			return
+		}
+		c.scan.allowedPairs = make(map[[2]string]bool, len(pairs))
+		for _, pair := range pairs {
+			c.scan.allowedPairs[pairKey(pair[0], pair[1])] = true
+		}
+	}
+}
+
+// WithMinOIRank excludes symbols ranked worse (numerically higher) than rank
+// by open interest from arbitrage scans, keeping results to markets liquid
+// enough to trade at size. Symbols without a rank are excluded unless
+// WithIncludeUnranked is set.
+func WithMinOIRank(rank int) Option {
+	return func(c *Client) {
+		c.scan.minOIRank = rank
+	}
+}
+
+// WithIncludeUnranked keeps symbols missing from OIRankings in scans filtered
+// by WithMinOIRank
+func WithIncludeUnranked(include bool) Option {
+	return func(c *Client) {
+		c.scan.includeUnranked = include
+	}
+}
+
+// WithMinExchanges excludes symbols reported by fewer than n exchanges from
+// arbitrage scans, as a robustness guard independent of the spread. The
+// count is taken after WithExchangeReconciliation, so under the default it is
+// the one Coverage reports, and before WithAllowedPairs narrows the pairs.
+// Defaults to 2, the minimum for any spread; lower values have no effect.
+func WithMinExchanges(n int) Option {
+	return func(c *Client) {
+		c.scan.minExchanges = n
+	}
+}
+
+// requiredExchanges returns how many exchanges must report a symbol for it
+// to be scanned
+func (s *scanConfig) requiredExchanges() int {
+	if s.minExchanges > 2 {
+		return s.minExchanges
+	}
+	return 2
+}
+
+// WithSortKey sets how all-symbol scans rank their results. Defaults to
+// SortBySpread.
+func WithSortKey(key SortKey) Option {
+	return func(c *Client) {
+		c.scan.sortKey = key
+	}
+}
+
+// WithIncludeZeroSpread keeps pairs whose rates are exactly equal in scans.
+// They are dropped by default, even with a non-positive minSpread, since a
+// zero spread can't be traded.
+func WithIncludeZeroSpread() Option {
+	return func(c *Client) {
+		c.scan.includeZeroSpread = true
+	}
+}
+
+// ExchangeReconciliation selects which exchanges scans consider when the
+// response's exchanges list and the keys of funding_rates disagree
+type ExchangeReconciliation int
+
+const (
+	// TrustRateKeys scans every exchange with rates, listed or not
+	TrustRateKeys ExchangeReconciliation = iota
+	// TrustExchangeList scans only exchanges in the exchanges list
+	TrustExchangeList
+	// IntersectExchanges scans only exchanges that are listed and have rates
+	IntersectExchanges
+)
+
+func (m ExchangeReconciliation) String() string {
+	switch m {
+	case TrustExchangeList:
+		return "exchange_list"
+	case IntersectExchanges:
+		return "intersect"
+	default:
+		return "rate_keys"
+	}
+}
+
+// WithExchangeReconciliation sets how scans reconcile the exchanges list with
+// the exchanges that have rates. Defaults to TrustRateKeys, so an exchange
+// with rates is scanned even if the list omits it. Since only exchanges with
+// rates can be paired, TrustExchangeList and IntersectExchanges select the
+// same exchanges for scans; both drop unlisted ones. Exchange counts for
+// WithMinExchanges are taken after reconciliation.
+func WithExchangeReconciliation(mode ExchangeReconciliation) Option {
+	return func(c *Client) {
+		c.scan.reconciliation = mode
+	}
+}
+
+// reconcile narrows rates, keyed by exchange, to the exchanges the
+// reconciliation mode lets scans consider
+func (s *scanConfig) reconcile(d *FundingRatesData, rates map[string]int) map[string]int {
+	if s.reconciliation == TrustRateKeys {
+		return rates
+	}
+
+	listed := make(map[string]bool, len(d.Exchanges.Exchanges))
+	for _, exchange := range d.Exchanges.Exchanges {
+		listed[exchange] = true
+	}
+	for exchange := range rates {
+		if !listed[exchange] {
+			delete(rates, exchange)
+		}
+	}
+	return rates
+}
+
+// quote returns rateBP, in basis points per the exchange's funding interval,
+// as the value scans compare: unchanged, or per hour under hourly
+// normalization
+func (s *scanConfig) quote(exchange string, rateBP int) float64 {
+	if !s.hourly || s.interval == nil {
+		return float64(rateBP)
+	}
+	return float64(rateBP) * float64(time.Hour) / float64(s.interval(exchange))
+}
+
+// allowsSymbol reports whether the scan may consider symbol
+func (s *scanConfig) allowsSymbol(d *FundingRatesData, symbol string) bool {
+	if s.minOIRank <= 0 {
+		return true
+	}
+	rank, err := d.OIRank(symbol)
+	if err != nil {
+		return s.includeUnranked
+	}
+	return rank <= s.minOIRank
+}
+
+// allowsPair reports whether the scan may pair two exchanges
+func (s *scanConfig) allowsPair(exchange1, exchange2 string) bool {
+	if s.allowedPairs == nil {
+		return true
+	}
+	return s.allowedPairs[pairKey(exchange1, exchange2)]
+}
+
+// pairKey orders an exchange pair so {A, B} and {B, A} compare equal
+func pairKey(exchange1, exchange2 string) [2]string {
+	if exchange1 > exchange2 {
+		exchange1, exchange2 = exchange2, exchange1
+	}
+	return [2]string{exchange1, exchange2}
+}
+
+// FindArbitrageGroupedBySymbol scans every symbol and returns the qualifying
+// opportunities keyed by symbol, each slice sorted best first by the
+// configured sort key. Symbols without an opportunity of at least minSpread are omitted.
+func (c *Client) FindArbitrageGroupedBySymbol(minSpread float64) (map[string][]ArbitrageOpportunity, error) {
+	return c.FindArbitrageGroupedBySymbolContext(context.Background(), minSpread)
+}
+
+// FindArbitrageGroupedBySymbolContext is FindArbitrageGroupedBySymbol under ctx
+func (c *Client) FindArbitrageGroupedBySymbolContext(ctx context.Context, minSpread float64) (map[string][]ArbitrageOpportunity, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	grouped := make(map[string][]ArbitrageOpportunity)
+	for _, symbol := range data.Symbols {
+		if opps := data.arbitrageOpportunities(symbol, minSpread, &c.scan); len(opps) > 0 {
+			c.sortScan(opps)
+			grouped[symbol] = opps
+		}
+	}
+	return grouped, nil
+}
+
+// FindAllArbitrageOpportunities scans every symbol and returns all
+// opportunities of at least minSpread, best first by the configured sort key
+func (c *Client) FindAllArbitrageOpportunities(minSpread float64) ([]ArbitrageOpportunity, error) {
+	return c.FindAllArbitrageOpportunitiesContext(context.Background(), minSpread)
+}
+
+// FindAllArbitrageOpportunitiesContext is FindAllArbitrageOpportunities under
+// ctx. If ctx is canceled after the rates were fetched, the opportunities
+// found so far are returned, sorted, together with ctx.Err().
+func (c *Client) FindAllArbitrageOpportunitiesContext(ctx context.Context, minSpread float64) ([]ArbitrageOpportunity, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var opportunities []ArbitrageOpportunity
+	for _, symbol := range data.Symbols {
+		if err := ctx.Err(); err != nil {
+			c.sortScan(opportunities)
+			return opportunities, err
+		}
+		opportunities = append(opportunities, data.arbitrageOpportunities(symbol, minSpread, &c.scan)...)
+	}
+
+	c.sortScan(opportunities)
+	return opportunities, nil
+}
+
+// BestOpportunityPerPair scans every symbol and returns, for each exchange
+// pair, its widest opportunity of at least minSpread, keyed by PairKey
+func (c *Client) BestOpportunityPerPair(minSpread float64) (map[string]ArbitrageOpportunity, error) {
+	return c.BestOpportunityPerPairContext(context.Background(), minSpread)
+}
+
+// BestOpportunityPerPairContext is BestOpportunityPerPair under ctx
+func (c *Client) BestOpportunityPerPairContext(ctx context.Context, minSpread float64) (map[string]ArbitrageOpportunity, error) {
+	opps, err := c.FindAllArbitrageOpportunitiesContext(ctx, minSpread)
+	if err != nil {
+		return nil, err
+	}
+
+	best := make(map[string]ArbitrageOpportunity)
+	for _, opp := range opps {
+		key := opp.PairKey()
+		if current, ok := best[key]; !ok || opp.Spread > current.Spread {
+			best[key] = opp
+		}
+	}
+	return best, nil
+}
+
+// FindTopArbitrageOpportunities is FindAllArbitrageOpportunities limited to the
+// n best opportunities. It keeps only the current n best in a bounded heap
+// while scanning, so memory stays proportional to n however many
+// opportunities the scan finds. A non-positive n returns nothing.
+func (c *Client) FindTopArbitrageOpportunities(minSpread float64, n int) ([]ArbitrageOpportunity, error) {
+	return c.FindTopArbitrageOpportunitiesContext(context.Background(), minSpread, n)
+}
+
+// FindTopArbitrageOpportunitiesContext is FindTopArbitrageOpportunities under ctx
+func (c *Client) FindTopArbitrageOpportunitiesContext(ctx context.Context, minSpread float64, n int) ([]ArbitrageOpportunity, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	top := &topOpportunities{value: c.rankValue}
+	for _, symbol := range data.Symbols {
+		for _, opp := range data.arbitrageOpportunities(symbol, minSpread, &c.scan) {
+			if top.Len() < n {
+				heap.Push(top, opp)
+			} else if ranksAbove(opp, top.opps[0], top.value) {
+				top.opps[0] = opp
+				heap.Fix(top, 0)
+			}
+		}
+	}
+
+	c.sortScan(top.opps)
+	return top.opps, nil
+}
+
+// topOpportunities is a heap with the lowest-ranked opportunity at the root,
+// so the weakest of the kept opportunities is the one evicted
+type topOpportunities struct {
+	opps  []ArbitrageOpportunity
+	value func(ArbitrageOpportunity) float64
+}
+
+func (h *topOpportunities) Len() int { return len(h.opps) }
+func (h *topOpportunities) Less(i, j int) bool {
+	return ranksAbove(h.opps[j], h.opps[i], h.value)
+}
+func (h *topOpportunities) Swap(i, j int)      { h.opps[i], h.opps[j] = h.opps[j], h.opps[i] }
+func (h *topOpportunities) Push(x interface{}) { h.opps = append(h.opps, x.(ArbitrageOpportunity)) }
+func (h *topOpportunities) Pop() interface{} {
+	last := h.opps[len(h.opps)-1]
+	h.opps = h.opps[:len(h.opps)-1]
+	return last
+}
+
+// BestArbitrageAcrossMarket scans every symbol and returns the single widest
+// opportunity of at least minSpread, without materializing the full list.
+// Ties go to the opportunity FindAllArbitrageOpportunities would list first
+// under SortBySpread. The bool is false if nothing qualifies.
+func (c *Client) BestArbitrageAcrossMarket(minSpread float64) (ArbitrageOpportunity, bool, error) {
+	return c.BestArbitrageAcrossMarketContext(context.Background(), minSpread)
+}
+
+// BestArbitrageAcrossMarketContext is BestArbitrageAcrossMarket under ctx
+func (c *Client) BestArbitrageAcrossMarketContext(ctx context.Context, minSpread float64) (ArbitrageOpportunity, bool, error) {
+	data, err := c.fetchFundingRates(ctx, nil)
+	if err != nil {
+		return ArbitrageOpportunity{}, false, err
+	}
+
+	var best ArbitrageOpportunity
+	found := false
+	for _, symbol := range data.Symbols {
+		for _, opp := range data.arbitrageOpportunities(symbol, minSpread, &c.scan) {
+			if !found || ranksAbove(opp, best, spreadValue) {
+				best, found = opp, true
+			}
+		}
+	}
+	return best, found, nil
+}
+
+// spreadValue ranks an opportunity by its raw Spread
+func spreadValue(o ArbitrageOpportunity) float64 {
+	return o.Spread
+}
+
+// rankValue returns the value o is ranked by under the configured sort key
+func (c *Client) rankValue(o ArbitrageOpportunity) float64 {
+	if c.scan.sortKey == SortByAnnualizedSpread {
+		return c.AnnualizedSpread(o)
+	}
+	return spreadValue(o)
+}
+
+// sortScan orders opportunities best first by the configured sort key
+func (c *Client) sortScan(opps []ArbitrageOpportunity) {
+	sortOpportunitiesBy(opps, c.rankValue)
+}
+
+// sortOpportunitiesBy orders opportunities by value descending, breaking ties
+// by symbol and exchange names so the order is reproducible
+func sortOpportunitiesBy(opps []ArbitrageOpportunity, value func(ArbitrageOpportunity) float64) {
+	sort.SliceStable(opps, func(i, j int) bool {
+		return ranksAbove(opps[i], opps[j], value)
+	})
+}
+
+// ranksAbove reports whether a sorts before b in sortOpportunitiesBy's order
+func ranksAbove(a, b ArbitrageOpportunity, value func(ArbitrageOpportunity) float64) bool {
+	if va, vb := value(a), value(b); va != vb {
+		return va > vb
+	}
+	if a.Symbol != b.Symbol {
+		return a.Symbol < b.Symbol
+	}
+	if a.Exchange1 != b.Exchange1 {
+		return a.Exchange1 < b.Exchange1
+	}
+	return a.Exchange2 < b.Exchange2
+}
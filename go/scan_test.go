@@ -0,0 +1,255 @@
+package onlyfunding
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// tiedData returns the example data with a TIE symbol whose rates give four
+// pairs the same 10bp spread and one pair a wider 20bp spread
+func tiedData() *FundingRatesData {
+	data := ExampleFundingRatesData()
+	data.Symbols = append(data.Symbols, "TIE")
+	data.FundingRates["binance_1_perp"]["TIE"] = 10
+	data.FundingRates["bybit_1_perp"]["TIE"] = 10
+	data.FundingRates["okx_1_perp"]["TIE"] = 20
+	data.FundingRates["hyperliquid_1_perp"]["TIE"] = 0
+	return data
+}
+
+func pairsOf(opps []ArbitrageOpportunity) [][2]string {
+	pairs := make([][2]string, len(opps))
+	for i, o := range opps {
+		pairs[i] = [2]string{o.Exchange1, o.Exchange2}
+	}
+	return pairs
+}
+
+func equalPairs(a, b [][2]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestArbitrageOpportunitiesTiedSpreadsAreOrdered(t *testing.T) {
+	want := [][2]string{
+		{"hyperliquid_1_perp", "okx_1_perp"},
+		{"binance_1_perp", "hyperliquid_1_perp"},
+		{"binance_1_perp", "okx_1_perp"},
+		{"bybit_1_perp", "hyperliquid_1_perp"},
+		{"bybit_1_perp", "okx_1_perp"},
+	}
+
+	data := tiedData()
+	for i := 0; i < 20; i++ {
+		got := pairsOf(data.arbitrageOpportunities("TIE", 0, &scanConfig{}))
+		if !equalPairs(got, want) {
+			t.Fatalf("call %d: got %v, want %v", i, got, want)
+		}
+	}
+}
+
+func TestSortOpportunitiesByBreaksTiesByExchange(t *testing.T) {
+	opps := tiedData().arbitrageOpportunities("TIE", 0, &scanConfig{})
+	want := pairsOf(opps)
+
+	reversed := make([]ArbitrageOpportunity, len(opps))
+	for i, o := range opps {
+		reversed[len(opps)-1-i] = o
+	}
+	sortOpportunitiesBy(reversed, spreadValue)
+	if got := pairsOf(reversed); !equalPairs(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestWithSortKeyAnnualizedReordersMixedIntervals(t *testing.T) {
+	// WIDE has the wider raw spread between two venues quoted per 8h; HOURLY
+	// has a narrower one against a venue quoted per hour, which is worth more
+	// once annualized
+	data := &FundingRatesData{
+		Symbols: []string{"WIDE", "HOURLY"},
+		Exchanges: ExchangesData{
+			Exchanges: []string{"binance_1_perp", "okx_1_perp", "hyperliquid_1_perp"},
+		},
+		FundingRates: map[string]map[string]int{
+			"binance_1_perp":     {"WIDE": 10, "HOURLY": 0},
+			"okx_1_perp":         {"WIDE": 0},
+			"hyperliquid_1_perp": {"HOURLY": 6},
+		},
+	}
+	intervals := WithFundingIntervals(map[string]time.Duration{"hyperliquid_1_perp": time.Hour})
+
+	symbolsOf := func(opps []ArbitrageOpportunity) []string {
+		symbols := make([]string, len(opps))
+		for i, o := range opps {
+			symbols[i] = o.Symbol
+		}
+		return symbols
+	}
+
+	tests := []struct {
+		name string
+		key  SortKey
+		want []string
+	}{
+		{"spread", SortBySpread, []string{"WIDE", "HOURLY"}},
+		{"annualized", SortByAnnualizedSpread, []string{"HOURLY", "WIDE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newStubClient(t, data, intervals, WithSortKey(tt.key))
+			opps, err := c.FindAllArbitrageOpportunities(0)
+			if err != nil {
+				t.Fatalf("FindAllArbitrageOpportunities error: %v", err)
+			}
+			if got := symbolsOf(opps); !equalStrings(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func hasPair(opps []ArbitrageOpportunity, exchange1, exchange2 string) (ArbitrageOpportunity, bool) {
+	for _, o := range opps {
+		if o.Exchange1 == exchange1 && o.Exchange2 == exchange2 {
+			return o, true
+		}
+	}
+	return ArbitrageOpportunity{}, false
+}
+
+func TestZeroSpreadPairs(t *testing.T) {
+	data := tiedData()
+
+	for _, minSpread := range []float64{0, -1} {
+		opps := data.arbitrageOpportunities("TIE", minSpread, &scanConfig{})
+		if _, ok := hasPair(opps, "binance_1_perp", "bybit_1_perp"); ok {
+			t.Errorf("minSpread %v: equal rates reported as an opportunity", minSpread)
+		}
+	}
+	if opps := data.arbitrageOpportunitiesBP("TIE", 0, &scanConfig{}); len(opps) != 5 {
+		t.Errorf("BP scan found %d opportunities, want 5 without the zero spread", len(opps))
+	}
+
+	c := newStubClient(t, data, WithIncludeZeroSpread())
+	opps, err := c.FindArbitrageOpportunities("TIE", 0)
+	if err != nil {
+		t.Fatalf("FindArbitrageOpportunities error: %v", err)
+	}
+	o, ok := hasPair(opps, "binance_1_perp", "bybit_1_perp")
+	if !ok {
+		t.Fatal("WithIncludeZeroSpread dropped the zero-spread pair")
+	}
+	if o.Spread != 0 || o.SpreadBP != 0 {
+		t.Errorf("zero-spread pair has Spread %v, SpreadBP %d", o.Spread, o.SpreadBP)
+	}
+	if last := opps[len(opps)-1]; last.Exchange1 != "binance_1_perp" || last.Exchange2 != "bybit_1_perp" {
+		t.Errorf("zero-spread pair not ranked last: %v", pairsOf(opps))
+	}
+}
+
+func TestNaNMinSpreadMatchesNothing(t *testing.T) {
+	data := tiedData()
+	if opps := data.arbitrageOpportunities("TIE", math.NaN(), &scanConfig{}); opps == nil || len(opps) != 0 {
+		t.Errorf("NaN minSpread returned %v, want an empty slice", opps)
+	}
+
+	c := newStubClient(t, data, WithIncludeZeroSpread())
+	opps, err := c.FindArbitrageOpportunities("TIE", math.NaN())
+	if err != nil {
+		t.Fatalf("FindArbitrageOpportunities error: %v", err)
+	}
+	if len(opps) != 0 {
+		t.Errorf("NaN minSpread matched %v", pairsOf(opps))
+	}
+	all, err := c.FindAllArbitrageOpportunities(math.NaN())
+	if err != nil {
+		t.Fatalf("FindAllArbitrageOpportunities error: %v", err)
+	}
+	if len(all) != 0 {
+		t.Errorf("NaN minSpread matched %d opportunities across symbols", len(all))
+	}
+}
+
+// benchmarkData returns a synthetic snapshot with many exchanges and symbols,
+// so a full scan finds tens of thousands of opportunities
+func benchmarkData() *FundingRatesData {
+	const exchanges, symbols = 20, 200
+	data := &FundingRatesData{FundingRates: make(map[string]map[string]int, exchanges)}
+	for s := 0; s < symbols; s++ {
+		data.Symbols = append(data.Symbols, fmt.Sprintf("SYM%03d", s))
+	}
+	for e := 0; e < exchanges; e++ {
+		exchange := fmt.Sprintf("exchange_%02d", e)
+		data.Exchanges.Exchanges = append(data.Exchanges.Exchanges, exchange)
+		rates := make(map[string]int, symbols)
+		for s, symbol := range data.Symbols {
+			rates[symbol] = (e*31+s*17)%97 - 48
+		}
+		data.FundingRates[exchange] = rates
+	}
+	return data
+}
+
+// BenchmarkFindTopArbitrageOpportunities measures the bounded heap scan;
+// compare its allocations with BenchmarkFindAllThenTruncate
+func BenchmarkFindTopArbitrageOpportunities(b *testing.B) {
+	c := newStubClient(b, benchmarkData(), WithCacheTTL(time.Hour))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := c.FindTopArbitrageOpportunities(0, 10); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkFindAllThenTruncate is the naive top-n: materialize and sort every
+// opportunity, then keep the first n
+func BenchmarkFindAllThenTruncate(b *testing.B) {
+	c := newStubClient(b, benchmarkData(), WithCacheTTL(time.Hour))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		opps, err := c.FindAllArbitrageOpportunities(0)
+		if err != nil {
+			b.Fatal(err)
+		}
+		_ = opps[:10]
+	}
+}
+
+func TestWithAllowedPairs(t *testing.T) {
+	all := len(ExampleFundingRatesData().arbitrageOpportunities("BTC", 0, &scanConfig{}))
+
+	tests := []struct {
+		name  string
+		pairs [][2]string
+		want  int
+	}{
+		{"nil", nil, all},
+		{"empty", [][2]string{}, all},
+		{"one pair reversed", [][2]string{{"okx_1_perp", "binance_1_perp"}}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newStubClient(t, ExampleFundingRatesData(), WithAllowedPairs(tt.pairs))
+			opps, err := c.FindArbitrageOpportunities("BTC", 0)
+			if err != nil {
+				t.Fatalf("FindArbitrageOpportunities error: %v", err)
+			}
+			if len(opps) != tt.want {
+				t.Errorf("found %d opportunities, want %d", len(opps), tt.want)
+			}
+		})
+	}
+}
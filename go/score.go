@@ -0,0 +1,66 @@
+package onlyfunding
+
+import "time"
+
+// ScoreWeights sets how much each factor counts in ScoreOpportunity. Weights
+// are relative; they need not sum to 1.
+type ScoreWeights struct {
+	Spread      float64
+	OIRank      float64
+	Persistence float64
+}
+
+// DefaultScoreWeights favors the spread, then liquidity, then durability
+var DefaultScoreWeights = ScoreWeights{Spread: 0.6, OIRank: 0.3, Persistence: 0.1}
+
+// Normalization scales for ScoreOpportunity: a factor scores 0.5 at its scale
+// and approaches 1 well beyond it
+const (
+	scoreSpreadScale      = 0.001 // 10 basis points per interval
+	scorePersistenceScale = time.Hour
+)
+
+// ScoreOpportunity blends o's spread and its symbol's open interest rank in
+// data into a single score in [0, 1] for ranking opportunities by
+// tradability rather than spread alone. Each factor is normalized to [0, 1]:
+// the spread as s/(s+10bp), so it saturates instead of letting one outlier
+// dominate, and the OI rank (see EffectiveOIRank) as 1/rank, so the largest
+// market scores 1. A symbol without a parseable rank scores 0 on it. The
+// factors are averaged with weights; persistence is not considered here, see
+// ScoreOpportunityWithHistory.
+func ScoreOpportunity(o ArbitrageOpportunity, data *FundingRatesData, weights ScoreWeights) float64 {
+	return ScoreOpportunityWithHistory(o, data, nil, weights)
+}
+
+// ScoreOpportunityWithHistory is ScoreOpportunity that also weighs how long
+// opportunities like o have lasted, from History.OpportunityPersistence,
+// normalized as d/(d+1h). Without enough history to estimate persistence, or
+// with a nil history, that factor is left out and the others are reweighted.
+func ScoreOpportunityWithHistory(o ArbitrageOpportunity, data *FundingRatesData, history *History, weights ScoreWeights) float64 {
+	var score, total float64
+	add := func(weight, value float64) {
+		if weight > 0 {
+			score += weight * value
+			total += weight
+		}
+	}
+
+	add(weights.Spread, o.Spread/(o.Spread+scoreSpreadScale))
+
+	oiScore := 0.0
+	if rank, err := data.EffectiveOIRank(o.Symbol); err == nil && rank > 0 {
+		oiScore = 1 / float64(rank)
+	}
+	add(weights.OIRank, oiScore)
+
+	if history != nil {
+		if d, ok := history.OpportunityPersistence(o.ID()); ok {
+			add(weights.Persistence, float64(d)/float64(d+scorePersistenceScale))
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+	return score / total
+}
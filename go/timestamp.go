@@ -0,0 +1,42 @@
+package onlyfunding
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// TimestampLayout is the layout of FundingRatesData.Timestamp, in UTC
+const TimestampLayout = "2006-01-02 15:04:05"
+
+// parseTimestamp parses a server timestamp. Besides TimestampLayout it
+// accepts RFC 3339 and Unix seconds, in case the API changes format.
+func parseTimestamp(s string) (time.Time, error) {
+	if t, err := time.ParseInLocation(TimestampLayout, s, time.UTC); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(secs, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid timestamp %q", s)
+}
+
+// Time returns the snapshot's server timestamp, parsed as TimestampLayout in
+// UTC, e.g. "2024-05-01 08:00:00". RFC 3339 and Unix seconds are accepted too.
+func (d *FundingRatesData) Time() (time.Time, error) {
+	return parseTimestamp(d.Timestamp)
+}
+
+// IsStale reports whether the snapshot is older than maxAge by its server
+// timestamp. A snapshot whose timestamp can't be parsed counts as stale,
+// since its age is unknown.
+func (d *FundingRatesData) IsStale(maxAge time.Duration) bool {
+	t, err := d.Time()
+	if err != nil {
+		return true
+	}
+	return time.Since(t) > maxAge
+}
@@ -0,0 +1,64 @@
+package onlyfunding
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFundingRatesDataTime(t *testing.T) {
+	want := time.Date(2024, 1, 15, 14, 30, 25, 0, time.UTC)
+	tests := []struct {
+		name      string
+		timestamp string
+		wantErr   bool
+	}{
+		{"layout", "2024-01-15 14:30:25", false},
+		{"rfc3339", "2024-01-15T14:30:25Z", false},
+		{"rfc3339 offset", "2024-01-15T15:30:25+01:00", false},
+		{"unix seconds", "1705329025", false},
+		{"empty", "", true},
+		{"garbage", "yesterday-ish", true},
+		{"layout without seconds", "2024-01-15 14:30", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &FundingRatesData{Timestamp: tt.timestamp}
+			got, err := d.Time()
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("Time() = %v, want error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Time() error: %v", err)
+			}
+			if !got.Equal(want) {
+				t.Errorf("Time() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestFundingRatesDataIsStale(t *testing.T) {
+	now := time.Now().UTC()
+	tests := []struct {
+		name      string
+		timestamp string
+		maxAge    time.Duration
+		want      bool
+	}{
+		{"fresh", now.Add(-time.Minute).Format(TimestampLayout), time.Hour, false},
+		{"old", now.Add(-2 * time.Hour).Format(TimestampLayout), time.Hour, true},
+		{"malformed", "not a timestamp", 24 * time.Hour, true},
+		{"empty", "", 24 * time.Hour, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &FundingRatesData{Timestamp: tt.timestamp}
+			if got := d.IsStale(tt.maxAge); got != tt.want {
+				t.Errorf("IsStale(%v) = %v, want %v", tt.maxAge, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package onlyfunding
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MaxPlausibleRate is the largest absolute raw rate Validate accepts. Raw
+// rates are in basis points, so this is 100% per funding interval.
+const MaxPlausibleRate = 10000
+
+// ValidationError lists every problem Validate found in a payload
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid funding rates data: %s", strings.Join(e.Problems, "; "))
+}
+
+// Validate sanity-checks decoded data before it is acted on. It reports empty
+// symbol or exchange lists, rates for exchanges missing from the exchange
+// list, and rates outside ±MaxPlausibleRate. All problems are returned
+// together as a *ValidationError.
+func (d *FundingRatesData) Validate() error {
+	var problems []string
+
+	if len(d.Symbols) == 0 {
+		problems = append(problems, "no symbols")
+	}
+	if len(d.Exchanges.Exchanges) == 0 {
+		problems = append(problems, "no exchanges")
+	}
+
+	listed := make(map[string]bool, len(d.Exchanges.Exchanges))
+	for _, exchange := range d.Exchanges.Exchanges {
+		listed[exchange] = true
+	}
+
+	exchanges := make([]string, 0, len(d.FundingRates))
+	for exchange := range d.FundingRates {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Strings(exchanges)
+
+	for _, exchange := range exchanges {
+		if !listed[exchange] {
+			problems = append(problems, fmt.Sprintf("rates for unlisted exchange %s", exchange))
+		}
+
+		symbols := make([]string, 0, len(d.FundingRates[exchange]))
+		for symbol := range d.FundingRates[exchange] {
+			symbols = append(symbols, symbol)
+		}
+		sort.Strings(symbols)
+
+		for _, symbol := range symbols {
+			rate := d.FundingRates[exchange][symbol]
+			if rate > MaxPlausibleRate || rate < -MaxPlausibleRate {
+				problems = append(problems, fmt.Sprintf("implausible rate %d for %s on %s", rate, symbol, exchange))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// QualityReport summarizes signs of a broken or stuck upstream feed that
+// Validate doesn't treat as errors
+type QualityReport struct {
+	// ZeroSpreads counts exchange pairs quoting a symbol at exactly the same rate
+	ZeroSpreads int
+	// DuplicateExchanges lists exchange keys that appear more than once in the
+	// exchange list or the display names
+	DuplicateExchanges []string
+	// StuckSymbols lists symbols reported by at least two exchanges that all
+	// quote the identical rate, a telltale of a frozen feed
+	StuckSymbols []string
+}
+
+// HasIssues reports whether the report found anything worth alerting on
+func (r QualityReport) HasIssues() bool {
+	return r.ZeroSpreads > 0 || len(r.DuplicateExchanges) > 0 || len(r.StuckSymbols) > 0
+}
+
+// DataQualityReport inspects data for zero spreads, duplicate exchange
+// entries and symbols with identical rates everywhere. These catch upstream
+// staleness that isn't visible in the timestamp.
+func DataQualityReport(data *FundingRatesData) QualityReport {
+	var report QualityReport
+
+	duplicates := make(map[string]bool)
+	seen := make(map[string]bool)
+	for _, exchange := range data.Exchanges.Exchanges {
+		if seen[exchange] {
+			duplicates[exchange] = true
+		}
+		seen[exchange] = true
+	}
+	seen = make(map[string]bool)
+	for _, info := range data.Exchanges.ExchangeNames {
+		if seen[info.Name] {
+			duplicates[info.Name] = true
+		}
+		seen[info.Name] = true
+	}
+	for exchange := range duplicates {
+		report.DuplicateExchanges = append(report.DuplicateExchanges, exchange)
+	}
+	sort.Strings(report.DuplicateExchanges)
+
+	for _, symbol := range data.Symbols {
+		rates := data.symbolRates(symbol)
+		if len(rates) < 2 {
+			continue
+		}
+
+		counts := make(map[int]int)
+		for _, rate := range rates {
+			counts[rate]++
+		}
+		for _, n := range counts {
+			report.ZeroSpreads += n * (n - 1) / 2
+		}
+		if len(counts) == 1 {
+			report.StuckSymbols = append(report.StuckSymbols, symbol)
+		}
+	}
+	sort.Strings(report.StuckSymbols)
+
+	return report
+}
@@ -0,0 +1,109 @@
+package onlyfunding
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// WatchlistClient wraps a Client for a fixed set of symbols, such as a
+// dashboard's watchlist. Each Fetch narrows the rates to the watchlist, using
+// server-side filtering when the client has it enabled, and keeps the result
+// so the per-symbol methods can be called repeatedly without a round trip. It
+// is safe for concurrent use.
+type WatchlistClient struct {
+	client  *Client
+	symbols []string
+	watched map[string]bool
+
+	mu     sync.RWMutex
+	latest *FundingRatesData
+}
+
+// NewWatchlistClient returns a WatchlistClient for symbols on top of client
+func NewWatchlistClient(client *Client, symbols []string) *WatchlistClient {
+	w := &WatchlistClient{
+		client:  client,
+		symbols: append([]string(nil), symbols...),
+		watched: make(map[string]bool, len(symbols)),
+	}
+	for _, symbol := range symbols {
+		w.watched[symbol] = true
+	}
+	return w
+}
+
+// Symbols returns the watchlist
+func (w *WatchlistClient) Symbols() []string {
+	return append([]string(nil), w.symbols...)
+}
+
+// Fetch fetches current rates for the watchlist and keeps them for the
+// per-symbol methods
+func (w *WatchlistClient) Fetch(ctx context.Context) (*FundingRatesData, error) {
+	data, err := w.client.GetFundingRatesFiltered(ctx, w.symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	w.latest = data.clone()
+	w.mu.Unlock()
+	return data, nil
+}
+
+// Latest returns a copy of the rates from the last successful Fetch. The bool
+// is false if nothing has been fetched yet.
+func (w *WatchlistClient) Latest() (*FundingRatesData, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.latest == nil {
+		return nil, false
+	}
+	return w.latest.clone(), true
+}
+
+// Rates returns every exchange's rate for symbol from the last Fetch, highest
+// first
+func (w *WatchlistClient) Rates(symbol string) ([]SymbolRate, error) {
+	var rates []SymbolRate
+	err := w.withLatest(symbol, func(d *FundingRatesData) {
+		rates = d.rankedRates(symbol)
+	})
+	return rates, err
+}
+
+// Opportunities returns the arbitrage opportunities for symbol from the last
+// Fetch, as FindArbitrageOpportunities would
+func (w *WatchlistClient) Opportunities(symbol string, minSpread float64) ([]ArbitrageOpportunity, error) {
+	var opps []ArbitrageOpportunity
+	err := w.withLatest(symbol, func(d *FundingRatesData) {
+		opps = d.arbitrageOpportunities(symbol, minSpread, &w.client.scan)
+	})
+	return opps, err
+}
+
+// Table renders FormatSymbolTable for symbol from the last Fetch
+func (w *WatchlistClient) Table(symbol string, opts ...FormatOption) (string, error) {
+	var table string
+	err := w.withLatest(symbol, func(d *FundingRatesData) {
+		table = d.FormatSymbolTable(symbol, opts...)
+	})
+	return table, err
+}
+
+// withLatest calls fn with the last fetched data under the read lock, after
+// checking symbol is watched and something has been fetched
+func (w *WatchlistClient) withLatest(symbol string, fn func(*FundingRatesData)) error {
+	if !w.watched[symbol] {
+		return fmt.Errorf("symbol %s is not on the watchlist", symbol)
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.latest == nil {
+		return fmt.Errorf("no rates fetched yet")
+	}
+	fn(w.latest)
+	return nil
+}